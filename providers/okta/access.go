@@ -0,0 +1,357 @@
+package okta
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// AccessStepType identifies how a step in an AccessPath grants access.
+type AccessStepType string
+
+const (
+	// AccessStepUser is a user assigned directly to the application
+	AccessStepUser AccessStepType = "USER"
+	// AccessStepGroup is membership of a group
+	AccessStepGroup AccessStepType = "GROUP"
+	// AccessStepGroupRule is a group rule that assigns members of the following group to the previous group
+	AccessStepGroupRule AccessStepType = "GROUP_RULE"
+)
+
+// AccessStep is a single user, group or group rule in an AccessPath.
+type AccessStep struct {
+	Type AccessStepType
+	ID   string
+	Name string
+}
+
+// AccessPath is one way a user gains access to an application, starting with the application
+// assignment. e.g. a user that is a member of "contractors", which the rule "contractors to eng"
+// adds to the assigned group "engineering", has the path:
+//
+//	group "engineering" <- rule "contractors to eng" <- group "contractors"
+type AccessPath []AccessStep
+
+func (p AccessPath) String() string {
+	steps := []string{}
+	for _, step := range p {
+		switch step.Type {
+		case AccessStepUser:
+			steps = append(steps, "direct assignment")
+		case AccessStepGroup:
+			steps = append(steps, fmt.Sprintf("group %q", step.Name))
+		case AccessStepGroupRule:
+			steps = append(steps, fmt.Sprintf("rule %q", step.Name))
+		}
+	}
+	return strings.Join(steps, " <- ")
+}
+
+// UserAccess is a user with access to an application and every path through which it is granted.
+type UserAccess struct {
+	UserID string
+	Login  string
+	Status string
+	Paths  []AccessPath
+}
+
+// AccessResolver expands application assignments into the users that have access.
+// Group members, group names, users and group rules are cached for the lifetime of the resolver,
+// so resolving several applications with the same resolver fetches each group once.
+// An AccessResolver is not safe for concurrent use.
+type AccessResolver struct {
+	client *Client
+
+	members map[string][]okta.GroupMember
+	// memberIDs holds the ids in members so rule paths can check membership without scanning the members
+	memberIDs map[string]map[string]struct{}
+	groups    map[string]string
+	users     map[string]*okta.UserGetSingleton
+	// rules maps group ids to the active group rules that assign users to them, nil until loaded
+	rules map[string][]okta.GroupRule
+}
+
+// NewAccessResolver returns a resolver with an empty cache.
+func (c *Client) NewAccessResolver() *AccessResolver {
+	return &AccessResolver{
+		client:    c,
+		members:   map[string][]okta.GroupMember{},
+		memberIDs: map[string]map[string]struct{}{},
+		groups:    map[string]string{},
+		users:     map[string]*okta.UserGetSingleton{},
+	}
+}
+
+// ResolveAppAccess returns the users with access to the application appID, see AccessResolver.Resolve.
+func (c *Client) ResolveAppAccess(ctx context.Context, appID string) ([]UserAccess, error) {
+	return c.NewAccessResolver().Resolve(ctx, appID)
+}
+
+// Resolve returns the users with access to the application appID sorted by login, each annotated
+// with the paths through which access is granted: a direct assignment or membership of an assigned group.
+// When an active group rule assigns members of another group the user belongs to into that group,
+// the path continues through the rule and source group, recursively.
+// Okta does not report whether a membership was added by a rule or by an administrator,
+// so rule paths show the rules that grant the membership rather than the one that added it.
+/*
+
+Example usage:
+
+access, err := client.ResolveAppAccess(ctx, "0oa1234")
+for _, user := range access {
+	for _, path := range user.Paths {
+		fmt.Println(user.Login, path) // alice@example.com group "engineering" <- rule "contractors to eng" <- group "contractors"
+	}
+}
+*/
+func (r *AccessResolver) Resolve(ctx context.Context, appID string) ([]UserAccess, error) {
+	ctx = withRateLimitRecorder(ctx)
+	access := map[string]*UserAccess{}
+	add := func(id, login, status string, path AccessPath) {
+		if access[id] == nil {
+			access[id] = &UserAccess{UserID: id, Login: login, Status: status}
+		}
+		access[id].Paths = append(access[id].Paths, path)
+	}
+
+	// users are embedded in the assignments to avoid a request per directly assigned user
+	for appUser, err := range All(ctx, r.client.ApplicationUsersAPI.ListApplicationUsers(ctx, appID).Expand("user")) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list okta application users: %w", err)
+		}
+		if appUser.GetScope() != "USER" {
+			continue
+		}
+
+		user, err := r.appUser(ctx, appUser)
+		if err != nil {
+			return nil, err
+		}
+		add(user.GetId(), user.Profile.GetLogin(), user.GetStatus(), AccessPath{{Type: AccessStepUser, ID: user.GetId()}})
+	}
+
+	for assignment, err := range All(ctx, r.client.ApplicationGroupsAPI.ListApplicationGroupAssignments(ctx, appID)) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list okta application group assignments: %w", err)
+		}
+
+		groupStep, err := r.groupStep(ctx, assignment.GetId())
+		if err != nil {
+			return nil, err
+		}
+
+		members, err := r.groupMembers(ctx, assignment.GetId())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			rulePaths, err := r.rulePaths(ctx, assignment.GetId(), member.GetId(), map[string]bool{assignment.GetId(): true})
+			if err != nil {
+				return nil, err
+			}
+
+			login := ""
+			if member.Profile != nil {
+				login = member.Profile.GetLogin()
+			}
+
+			if len(rulePaths) == 0 {
+				add(member.GetId(), login, member.GetStatus(), AccessPath{groupStep})
+			}
+			for _, rulePath := range rulePaths {
+				add(member.GetId(), login, member.GetStatus(), append(AccessPath{groupStep}, rulePath...))
+			}
+		}
+	}
+
+	users := []UserAccess{}
+	for _, user := range access {
+		users = append(users, *user)
+	}
+	slices.SortFunc(users, func(a, b UserAccess) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a.Login), strings.ToLower(b.Login)), strings.Compare(a.UserID, b.UserID))
+	})
+
+	return users, nil
+}
+
+// rulePaths returns the chains of group rules and source groups through which userID is assigned to
+// groupID. visited holds the groups already on the path so that rules assigning users in a cycle terminate.
+func (r *AccessResolver) rulePaths(ctx context.Context, groupID, userID string, visited map[string]bool) ([]AccessPath, error) {
+	rules, err := r.groupRules(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []AccessPath{}
+	for _, rule := range rules {
+		for _, sourceID := range ruleSourceGroups(rule) {
+			if visited[sourceID] {
+				continue
+			}
+
+			member, err := r.isGroupMember(ctx, sourceID, userID)
+			if err != nil {
+				return nil, err
+			}
+			if !member {
+				continue
+			}
+
+			sourceStep, err := r.groupStep(ctx, sourceID)
+			if err != nil {
+				return nil, err
+			}
+			path := AccessPath{{Type: AccessStepGroupRule, ID: rule.GetId(), Name: rule.GetName()}, sourceStep}
+
+			visited[sourceID] = true
+			sourcePaths, err := r.rulePaths(ctx, sourceID, userID, visited)
+			delete(visited, sourceID)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(sourcePaths) == 0 {
+				paths = append(paths, path)
+			}
+			for _, sourcePath := range sourcePaths {
+				paths = append(paths, append(slices.Clone(path), sourcePath...))
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+func (r *AccessResolver) groupMembers(ctx context.Context, groupID string) ([]okta.GroupMember, error) {
+	if members, ok := r.members[groupID]; ok {
+		return members, nil
+	}
+
+	members, err := r.client.ListGroupUsers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	r.members[groupID] = members
+	return members, nil
+}
+
+func (r *AccessResolver) isGroupMember(ctx context.Context, groupID, userID string) (bool, error) {
+	ids, ok := r.memberIDs[groupID]
+	if !ok {
+		members, err := r.groupMembers(ctx, groupID)
+		if err != nil {
+			return false, err
+		}
+
+		ids = make(map[string]struct{}, len(members))
+		for _, member := range members {
+			ids[member.GetId()] = struct{}{}
+		}
+		r.memberIDs[groupID] = ids
+	}
+
+	_, ok = ids[userID]
+	return ok, nil
+}
+
+func (r *AccessResolver) groupStep(ctx context.Context, groupID string) (AccessStep, error) {
+	name, ok := r.groups[groupID]
+	if !ok {
+		group, resp, err := r.client.GroupAPI.GetGroup(ctx, groupID).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			return AccessStep{}, fmt.Errorf("failed to get okta group %q: %w", groupID, err)
+		}
+		name = groupName(*group)
+		r.groups[groupID] = name
+	}
+	return AccessStep{Type: AccessStepGroup, ID: groupID, Name: name}, nil
+}
+
+// appUser returns the user embedded in an application assignment, or gets the user when it is not embedded.
+func (r *AccessResolver) appUser(ctx context.Context, appUser okta.AppUser) (*okta.UserGetSingleton, error) {
+	if embedded, ok := appUser.GetEmbedded()["user"]; ok {
+		data, err := json.Marshal(embedded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode embedded okta user %q: %w", appUser.GetId(), err)
+		}
+
+		user := &okta.UserGetSingleton{}
+		if err := json.Unmarshal(data, user); err != nil {
+			return nil, fmt.Errorf("failed to decode embedded okta user %q: %w", appUser.GetId(), err)
+		}
+		r.users[user.GetId()] = user
+		return user, nil
+	}
+
+	return r.user(ctx, appUser.GetId())
+}
+
+func (r *AccessResolver) user(ctx context.Context, userID string) (*okta.UserGetSingleton, error) {
+	if user, ok := r.users[userID]; ok {
+		return user, nil
+	}
+
+	user, resp, err := r.client.UserAPI.GetUser(ctx, userID).Execute()
+	if err := apiError(ctx, resp, err); err != nil {
+		return nil, fmt.Errorf("failed to get okta user %q: %w", userID, err)
+	}
+	r.users[userID] = user
+	return user, nil
+}
+
+// groupRules returns the active group rules that assign users to groupID, loading all rules on first use.
+func (r *AccessResolver) groupRules(ctx context.Context, groupID string) ([]okta.GroupRule, error) {
+	if r.rules == nil {
+		rules := map[string][]okta.GroupRule{}
+		for rule, err := range All(ctx, r.client.GroupAPI.ListGroupRules(ctx)) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list okta group rules: %w", err)
+			}
+			if rule.GetStatus() != "ACTIVE" || rule.Actions == nil || rule.Actions.AssignUserToGroups == nil {
+				continue
+			}
+			for _, target := range rule.Actions.AssignUserToGroups.GroupIds {
+				rules[target] = append(rules[target], rule)
+			}
+		}
+		r.rules = rules
+	}
+	return r.rules[groupID], nil
+}
+
+var (
+	memberOfGroupExpr = regexp.MustCompile(`isMemberOf(?:Any)?Group\(([^)]*)\)`)
+	quotedExpr        = regexp.MustCompile(`"([^"]+)"`)
+)
+
+// ruleSourceGroups returns the ids of the groups a rule's conditions require membership of,
+// from the people condition and isMemberOfGroup or isMemberOfAnyGroup calls in its expression.
+func ruleSourceGroups(rule okta.GroupRule) []string {
+	groups := []string{}
+	if rule.Conditions == nil {
+		return groups
+	}
+
+	if people := rule.Conditions.People; people != nil && people.Groups != nil {
+		groups = append(groups, people.Groups.Include...)
+	}
+
+	if expr := rule.Conditions.Expression; expr != nil {
+		for _, call := range memberOfGroupExpr.FindAllStringSubmatch(expr.GetValue(), -1) {
+			for _, id := range quotedExpr.FindAllStringSubmatch(call[1], -1) {
+				groups = append(groups, id[1])
+			}
+		}
+	}
+
+	slices.Sort(groups)
+	return slices.Compact(groups)
+}
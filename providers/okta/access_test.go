@@ -0,0 +1,152 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessServer(t *testing.T, rules ...oktatest.GroupRule) (*Client, *oktatest.Server) {
+	return newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com"},
+			{ID: "u2", Login: "bob@example.com"},
+			{ID: "u3", Login: "carol@example.com"},
+			{ID: "u4", Login: "dave@example.com"},
+			{ID: "u5", Login: "erin@example.com", Status: "SUSPENDED"},
+		},
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: "engineering"},
+			{ID: "g2", Name: "contractors"},
+			{ID: "g3", Name: "vendors"},
+			{ID: "g4", Name: "other"},
+		},
+		Apps: []oktatest.App{{ID: "app1", Label: "wiki"}, {ID: "app2", Label: "chat"}},
+		GroupRules: append([]oktatest.GroupRule{
+			{ID: "r1", Name: "contractors to eng", Groups: []string{"contractors"}, Assign: []string{"engineering"}},
+			{ID: "r2", Name: "vendors to contractors", Groups: []string{"vendors"}, Assign: []string{"contractors"}},
+			{ID: "r3", Name: "inactive", Status: "INACTIVE", Groups: []string{"other"}, Assign: []string{"engineering"}},
+		}, rules...),
+		Members: map[string][]string{
+			"engineering": {"u1", "u2", "u3"},
+			"contractors": {"u2", "u3"},
+			"vendors":     {"u3"},
+			"other":       {"u1", "u4"},
+		},
+		AppGroups: map[string][]string{"wiki": {"engineering", "contractors"}, "chat": {"engineering"}},
+		AppUsers:  map[string][]string{"wiki": {"erin@example.com", "alice@example.com"}},
+	})
+}
+
+// accessPaths renders the resolved access as login to path strings.
+func accessPaths(access []UserAccess) map[string][]string {
+	out := map[string][]string{}
+	for _, user := range access {
+		for _, path := range user.Paths {
+			out[user.Login] = append(out[user.Login], path.String())
+		}
+	}
+	return out
+}
+
+func TestResolveAppAccess(t *testing.T) {
+	client, _ := newAccessServer(t)
+
+	access, err := client.ResolveAppAccess(context.Background(), "app1")
+	require.NoError(t, err)
+
+	logins := []string{}
+	for _, user := range access {
+		logins = append(logins, user.Login)
+	}
+	assert.Equal(t, []string{"alice@example.com", "bob@example.com", "carol@example.com", "erin@example.com"}, logins)
+	assert.Equal(t, "SUSPENDED", access[3].Status)
+
+	assert.Equal(t, map[string][]string{
+		"alice@example.com": {
+			"direct assignment",
+			`group "engineering"`,
+		},
+		"bob@example.com": {
+			`group "engineering" <- rule "contractors to eng" <- group "contractors"`,
+			`group "contractors"`,
+		},
+		"carol@example.com": {
+			`group "engineering" <- rule "contractors to eng" <- group "contractors" <- rule "vendors to contractors" <- group "vendors"`,
+			`group "contractors" <- rule "vendors to contractors" <- group "vendors"`,
+		},
+		"erin@example.com": {
+			"direct assignment",
+		},
+	}, accessPaths(access))
+
+	assert.Equal(t, AccessPath{
+		{Type: AccessStepGroup, ID: "g2", Name: "contractors"},
+		{Type: AccessStepGroupRule, ID: "r2", Name: "vendors to contractors"},
+		{Type: AccessStepGroup, ID: "g3", Name: "vendors"},
+	}, access[2].Paths[1])
+}
+
+func TestResolveAppAccessRuleCycle(t *testing.T) {
+	client, _ := newAccessServer(t, oktatest.GroupRule{ID: "r4", Name: "eng to contractors", Groups: []string{"engineering"}, Assign: []string{"contractors"}})
+
+	access, err := client.ResolveAppAccess(context.Background(), "app2")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"alice@example.com": {`group "engineering"`},
+		"bob@example.com":   {`group "engineering" <- rule "contractors to eng" <- group "contractors"`},
+		"carol@example.com": {`group "engineering" <- rule "contractors to eng" <- group "contractors" <- rule "vendors to contractors" <- group "vendors"`},
+	}, accessPaths(access))
+}
+
+func TestAccessResolverCache(t *testing.T) {
+	client, server := newAccessServer(t)
+	ctx := context.Background()
+
+	resolver := client.NewAccessResolver()
+	_, err := resolver.Resolve(ctx, "app1")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(ctx, "app2")
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for _, r := range server.Requests() {
+		if !strings.Contains(r, "/apps/") {
+			counts[r]++
+		}
+	}
+	for request, count := range counts {
+		assert.Equal(t, 1, count, request)
+	}
+	assert.Equal(t, 1, counts["GET /api/v1/groups/rules"])
+	assert.Equal(t, 1, counts["GET /api/v1/groups/g1/users"])
+
+	// directly assigned users are embedded in the application assignments
+	assert.Zero(t, countRequests(server, "GET /api/v1/users/"))
+}
+
+func TestResolveAppAccessNotFound(t *testing.T) {
+	client, _ := newAccessServer(t)
+
+	_, err := client.ResolveAppAccess(context.Background(), "missing")
+	assert.ErrorContains(t, err, "failed to list okta application users")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestResolveAppAccessAPIError(t *testing.T) {
+	client, server := newAccessServer(t)
+	server.InjectFault(oktatest.Fault{Method: http.MethodGet, PathPrefix: "/api/v1/groups/g1", Status: http.StatusForbidden})
+
+	_, err := client.ResolveAppAccess(context.Background(), "app1")
+	assert.ErrorContains(t, err, `failed to get okta group "g1"`)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,355 @@
+package okta
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// Application is the sign on mode independent part of an Okta app.
+type Application struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	// Status is ACTIVE or INACTIVE
+	Status string `json:"status"`
+	// SignOnMode is the app's type, e.g. OPENID_CONNECT or SAML_2_0
+	SignOnMode string `json:"signOnMode"`
+}
+
+// ListApplications lists the org's apps. WithQuery matches the start of the app's name or label
+// and WithFilter supports filtering on status, e.g. Eq("status", "ACTIVE").
+func (c *Client) ListApplications(ctx context.Context, opts ...ListOpt) ([]Application, error) {
+	ctx = withRateLimitRecorder(ctx)
+	query, err := applyListOpts(c.ApplicationAPI.ListApplications(ctx), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := []Application{}
+	for app, err := range All(ctx, query) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list okta applications: %w", err)
+		}
+
+		// the SDK returns a union of the app types, all of which share these fields
+		data, err := json.Marshal(app)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal okta application: %w", err)
+		}
+		application := Application{}
+		if err := json.Unmarshal(data, &application); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal okta application: %w", err)
+		}
+		apps = append(apps, application)
+	}
+
+	return apps, nil
+}
+
+// ApplicationsByLabel returns the apps labeled label, ignoring case. Labels are not unique so more than one app may be returned.
+func (c *Client) ApplicationsByLabel(ctx context.Context, label string, opts ...ListOpt) ([]Application, error) {
+	apps, err := c.ListApplications(ctx, append(opts, WithQuery(label))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(apps, func(app Application) bool {
+		return !strings.EqualFold(app.Label, label)
+	}), nil
+}
+
+// AppGroupAssignment is a group assigned to an app.
+type AppGroupAssignment struct {
+	GroupID   string `json:"groupId"`
+	GroupName string `json:"groupName"`
+	// Priority orders the assignments when a user is assigned to the app through more than one group,
+	// the group with the lowest priority number has its app profile applied
+	Priority int32 `json:"priority"`
+}
+
+// ListAppGroups lists the groups assigned to the app, in priority order. The assigned groups are expanded
+// to read their names, passing WithExpand without "group" leaves GroupName empty.
+func (c *Client) ListAppGroups(ctx context.Context, appID string, opts ...ListOpt) ([]AppGroupAssignment, error) {
+	ctx = withRateLimitRecorder(ctx)
+	query, err := applyListOpts(c.ApplicationGroupsAPI.ListApplicationGroupAssignments(ctx, appID).Expand("group"), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := []AppGroupAssignment{}
+	for assignment, err := range All(ctx, query) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list okta application groups: %w", err)
+		}
+
+		a := AppGroupAssignment{GroupID: assignment.GetId(), Priority: assignment.GetPriority()}
+		if profile, ok := assignment.Embedded["group"]["profile"].(map[string]any); ok {
+			a.GroupName, _ = profile["name"].(string)
+		}
+		assignments = append(assignments, a)
+	}
+
+	slices.SortStableFunc(assignments, func(a, b AppGroupAssignment) int {
+		return cmp.Compare(a.Priority, b.Priority)
+	})
+	return assignments, nil
+}
+
+type AssignAppGroupOpt func(okta.ApiAssignGroupToApplicationRequest) okta.ApiAssignGroupToApplicationRequest
+
+// WithPriority assigns the group at the priority, Okta assigns the next priority when it is not set.
+func WithPriority(priority int32) AssignAppGroupOpt {
+	return func(r okta.ApiAssignGroupToApplicationRequest) okta.ApiAssignGroupToApplicationRequest {
+		assignment := okta.ApplicationGroupAssignment{}
+		assignment.SetPriority(priority)
+		return r.ApplicationGroupAssignment(assignment)
+	}
+}
+
+// AssignAppGroup assigns the group to the app. Assigning a group that is already assigned updates its priority.
+func (c *Client) AssignAppGroup(ctx context.Context, appID, groupID string, opts ...AssignAppGroupOpt) error {
+	ctx = withRateLimitRecorder(ctx)
+	query := c.ApplicationGroupsAPI.AssignGroupToApplication(ctx, appID, groupID)
+	for _, opt := range opts {
+		query = opt(query)
+	}
+
+	_, resp, err := query.Execute()
+	if err := apiError(ctx, resp, err); err != nil {
+		return fmt.Errorf("failed to assign okta group %q to application %q: %w", groupID, appID, err)
+	}
+	return nil
+}
+
+// UnassignAppGroup removes the group's assignment from the app, unassigning a group that is not assigned is a no-op.
+func (c *Client) UnassignAppGroup(ctx context.Context, appID, groupID string) error {
+	ctx = withRateLimitRecorder(ctx)
+	resp, err := c.ApplicationGroupsAPI.UnassignApplicationFromGroup(ctx, appID, groupID).Execute()
+	err = apiError(ctx, resp, err)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to unassign okta group %q from application %q: %w", groupID, appID, err)
+	}
+	return nil
+}
+
+// AppGroupChange is a group assignment that is added, updated or removed.
+type AppGroupChange struct {
+	GroupID   string `json:"groupId"`
+	GroupName string `json:"groupName"`
+	Priority  int32  `json:"priority"`
+	// PreviousPriority is the priority before an update
+	PreviousPriority *int32 `json:"previousPriority,omitempty"`
+}
+
+// AppGroupPlan describes the changes required to assign the desired groups to an app.
+type AppGroupPlan struct {
+	AppID string `json:"appId"`
+	// Assign are the desired groups that are not assigned, in priority order
+	Assign []AppGroupChange `json:"assign"`
+	// Update are the assigned groups whose priority changes, in priority order
+	Update []AppGroupChange `json:"update"`
+	// Unassign are the assigned groups that are not desired, only set with WithPrune
+	Unassign []AppGroupChange `json:"unassign"`
+	// Unchanged is the number of desired groups that are already assigned with the desired priority
+	Unchanged int `json:"unchanged"`
+	// DryRun is true when the plan was computed without modifying the app
+	DryRun bool `json:"dryRun"`
+}
+
+// HasChanges reports whether applying the plan modifies the app.
+func (p *AppGroupPlan) HasChanges() bool {
+	return len(p.Assign) > 0 || len(p.Update) > 0 || len(p.Unassign) > 0
+}
+
+// String renders the plan for dry run output, one change per line.
+func (p *AppGroupPlan) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "application %s:", p.AppID)
+	if !p.HasChanges() {
+		fmt.Fprintf(b, " no changes, %d groups unchanged\n", p.Unchanged)
+		return b.String()
+	}
+	b.WriteString("\n")
+
+	for _, change := range p.Unassign {
+		fmt.Fprintf(b, "  - unassign group %q (%s)\n", change.GroupName, change.GroupID)
+	}
+	for _, change := range p.Assign {
+		fmt.Fprintf(b, "  + assign group %q (%s) with priority %d\n", change.GroupName, change.GroupID, change.Priority)
+	}
+	for _, change := range p.Update {
+		fmt.Fprintf(b, "  ~ update group %q (%s) priority %d -> %d\n", change.GroupName, change.GroupID, *change.PreviousPriority, change.Priority)
+	}
+	fmt.Fprintf(b, "  %d groups unchanged\n", p.Unchanged)
+	return b.String()
+}
+
+type ensureAppGroupsOptions struct {
+	dryRun bool
+	prune  bool
+}
+
+type EnsureAppGroupsOpt func(*ensureAppGroupsOptions)
+
+// WithAppGroupsDryRun computes the plan of EnsureAppGroups without making any of its changes.
+func WithAppGroupsDryRun(dryRun bool) EnsureAppGroupsOpt {
+	return func(o *ensureAppGroupsOptions) {
+		o.dryRun = dryRun
+	}
+}
+
+// WithPrune also unassigns groups that are not desired from the app in EnsureAppGroups.
+func WithPrune(prune bool) EnsureAppGroupsOpt {
+	return func(o *ensureAppGroupsOptions) {
+		o.prune = prune
+	}
+}
+
+// EnsureAppGroups assigns the desired groups, a map of group names to priorities, to the app and updates the
+// priority of assigned groups that differ. Group names are matched case insensitively, as Okta does, and every
+// desired group must exist. Groups that are not desired are left assigned unless WithPrune is set.
+// The returned plan lists the changes that were made, or would be when WithAppGroupsDryRun is set. When applying
+// the plan fails part way the plan is returned along with the error so callers can see what was attempted.
+/*
+
+Example usage:
+
+apps, err := client.ApplicationsByLabel(ctx, "Inventory")
+
+plan, err := client.EnsureAppGroups(ctx, apps[0].ID, map[string]int32{
+	"inventory-admins": 0,
+	"inventory-users":  1,
+}, okta.WithAppGroupsDryRun(true))
+
+fmt.Print(plan)
+*/
+func (c *Client) EnsureAppGroups(ctx context.Context, appID string, desired map[string]int32, opts ...EnsureAppGroupsOpt) (*AppGroupPlan, error) {
+	options := &ensureAppGroupsOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	names := []string{}
+	for name := range desired {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	// GroupsByName matches names case insensitively so the desired groups are too
+	priorities := map[string]int32{}
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if _, ok := priorities[key]; ok {
+			return nil, fmt.Errorf("okta group %q is desired more than once with different case", name)
+		}
+		priorities[key] = desired[name]
+	}
+
+	groups := []okta.Group{}
+	if len(names) > 0 {
+		var err error
+		if groups, err = c.GroupsByName(ctx, names, 20); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := c.ListAppGroups(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &AppGroupPlan{
+		AppID:    appID,
+		Assign:   []AppGroupChange{},
+		Update:   []AppGroupChange{},
+		Unassign: []AppGroupChange{},
+		DryRun:   options.dryRun,
+	}
+
+	assigned := map[string]AppGroupAssignment{}
+	for _, assignment := range current {
+		assigned[assignment.GroupID] = assignment
+	}
+
+	wanted := map[string]bool{}
+	resolved := map[string]bool{}
+	for _, group := range groups {
+		name := groupName(group)
+		priority, ok := priorities[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		wanted[group.GetId()] = true
+		resolved[strings.ToLower(name)] = true
+
+		change := AppGroupChange{GroupID: group.GetId(), GroupName: name, Priority: priority}
+		assignment, ok := assigned[group.GetId()]
+		switch {
+		case !ok:
+			plan.Assign = append(plan.Assign, change)
+		case assignment.Priority != priority:
+			change.PreviousPriority = &assignment.Priority
+			plan.Update = append(plan.Update, change)
+		default:
+			plan.Unchanged++
+		}
+	}
+
+	// a desired group that did not resolve must never be unassigned by the prune below
+	missing := []string{}
+	for _, name := range names {
+		if !resolved[strings.ToLower(name)] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &GroupsNotFoundError{Names: missing}
+	}
+
+	if options.prune {
+		for _, assignment := range current {
+			if !wanted[assignment.GroupID] {
+				plan.Unassign = append(plan.Unassign, AppGroupChange{GroupID: assignment.GroupID, GroupName: assignment.GroupName, Priority: assignment.Priority})
+			}
+		}
+	}
+
+	byPriority := func(a, b AppGroupChange) int {
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), cmp.Compare(a.GroupName, b.GroupName))
+	}
+	slices.SortFunc(plan.Assign, byPriority)
+	slices.SortFunc(plan.Update, byPriority)
+
+	if options.dryRun {
+		return plan, nil
+	}
+
+	for _, change := range plan.Unassign {
+		if err := c.UnassignAppGroup(ctx, appID, change.GroupID); err != nil {
+			return plan, err
+		}
+	}
+
+	// priorities are applied lowest first so assignments that shift the others end up in the desired order
+	changes := slices.SortedFunc(slices.Values(slices.Concat(plan.Assign, plan.Update)), byPriority)
+	for _, change := range changes {
+		if err := c.AssignAppGroup(ctx, appID, change.GroupID, WithPriority(change.Priority)); err != nil {
+			return plan, err
+		}
+	}
+
+	return plan, nil
+}
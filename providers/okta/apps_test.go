@@ -0,0 +1,203 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAppsServer(t *testing.T) (*Client, *oktatest.Server) {
+	return newSeededClient(t, oktatest.Fixtures{
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: "inventory-admins"},
+			{ID: "g2", Name: "inventory-users"},
+			{ID: "g3", Name: "auditors"},
+		},
+		Apps: []oktatest.App{
+			{ID: "app1", Label: "Inventory"},
+			{ID: "app2", Label: "Inventory Reports", Status: "INACTIVE"},
+			{ID: "app3", Label: "inventory"},
+			{ID: "app4", Label: "Billing"},
+		},
+		AppGroups: map[string][]string{"app1": {"inventory-admins", "inventory-users"}},
+	})
+}
+
+func appIDs(apps []Application) []string {
+	ids := []string{}
+	for _, app := range apps {
+		ids = append(ids, app.ID)
+	}
+	return ids
+}
+
+func TestListApplications(t *testing.T) {
+	client, _ := newAppsServer(t)
+	ctx := context.Background()
+
+	apps, err := client.ListApplications(ctx, WithPageSize(2))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app1", "app2", "app3", "app4"}, appIDs(apps))
+	assert.Equal(t, Application{ID: "app1", Name: "oidc_client", Label: "Inventory", Status: "ACTIVE", SignOnMode: "OPENID_CONNECT"}, apps[0])
+
+	apps, err = client.ListApplications(ctx, WithFilter(Eq("status", "INACTIVE")))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app2"}, appIDs(apps))
+
+	apps, err = client.ApplicationsByLabel(ctx, "INVENTORY")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app1", "app3"}, appIDs(apps))
+
+	apps, err = client.ApplicationsByLabel(ctx, "Payroll")
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+}
+
+func TestAppGroups(t *testing.T) {
+	client, server := newAppsServer(t)
+	ctx := context.Background()
+
+	assignments, err := client.ListAppGroups(ctx, "app1")
+	require.NoError(t, err)
+	assert.Equal(t, []AppGroupAssignment{
+		{GroupID: "g1", GroupName: "inventory-admins", Priority: 0},
+		{GroupID: "g2", GroupName: "inventory-users", Priority: 1},
+	}, assignments)
+
+	require.NoError(t, client.AssignAppGroup(ctx, "app1", "g3", WithPriority(0)))
+	assert.Equal(t, []string{"g3", "g1", "g2"}, server.AppGroups("app1"))
+
+	require.NoError(t, client.AssignAppGroup(ctx, "app1", "g3"))
+	assert.Equal(t, []string{"g1", "g2", "g3"}, server.AppGroups("app1"))
+
+	require.NoError(t, client.UnassignAppGroup(ctx, "app1", "g1"))
+	require.NoError(t, client.UnassignAppGroup(ctx, "app1", "g1"), "unassigning a group that is not assigned is a no-op")
+	assert.Equal(t, []string{"g2", "g3"}, server.AppGroups("app1"))
+
+	_, err = client.ListAppGroups(ctx, "missing")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/apps/app1/groups/g1", Status: http.StatusForbidden})
+	assert.ErrorIs(t, client.AssignAppGroup(ctx, "app1", "g1"), ErrForbidden)
+}
+
+func TestEnsureAppGroups(t *testing.T) {
+	tests := map[string]struct {
+		desired       map[string]int32
+		opts          []EnsureAppGroupsOpt
+		wantAssign    []AppGroupChange
+		wantUpdate    []AppGroupChange
+		wantUnassign  []AppGroupChange
+		wantUnchanged int
+		wantGroups    []string
+		wantMutations []string
+	}{
+		"already assigned": {
+			desired:       map[string]int32{"inventory-admins": 0, "inventory-users": 1},
+			wantUnchanged: 2,
+			wantGroups:    []string{"g1", "g2"},
+			wantMutations: []string{},
+		},
+		"assign and update": {
+			desired:       map[string]int32{"auditors": 0, "inventory-admins": 1},
+			wantAssign:    []AppGroupChange{{GroupID: "g3", GroupName: "auditors", Priority: 0}},
+			wantUpdate:    []AppGroupChange{{GroupID: "g1", GroupName: "inventory-admins", Priority: 1, PreviousPriority: okta.PtrInt32(0)}},
+			wantGroups:    []string{"g3", "g1", "g2"},
+			wantMutations: []string{"PUT /api/v1/apps/app1/groups/g3", "PUT /api/v1/apps/app1/groups/g1"},
+		},
+		"prune": {
+			desired:       map[string]int32{"auditors": 0, "inventory-admins": 1},
+			opts:          []EnsureAppGroupsOpt{WithPrune(true)},
+			wantAssign:    []AppGroupChange{{GroupID: "g3", GroupName: "auditors", Priority: 0}},
+			wantUpdate:    []AppGroupChange{{GroupID: "g1", GroupName: "inventory-admins", Priority: 1, PreviousPriority: okta.PtrInt32(0)}},
+			wantUnassign:  []AppGroupChange{{GroupID: "g2", GroupName: "inventory-users", Priority: 1}},
+			wantGroups:    []string{"g3", "g1"},
+			wantMutations: []string{"DELETE /api/v1/apps/app1/groups/g2", "PUT /api/v1/apps/app1/groups/g3", "PUT /api/v1/apps/app1/groups/g1"},
+		},
+		"dry run": {
+			desired:       map[string]int32{"auditors": 0},
+			opts:          []EnsureAppGroupsOpt{WithPrune(true), WithAppGroupsDryRun(true)},
+			wantAssign:    []AppGroupChange{{GroupID: "g3", GroupName: "auditors", Priority: 0}},
+			wantUnassign:  []AppGroupChange{{GroupID: "g1", GroupName: "inventory-admins", Priority: 0}, {GroupID: "g2", GroupName: "inventory-users", Priority: 1}},
+			wantGroups:    []string{"g1", "g2"},
+			wantMutations: []string{},
+		},
+		"names differing in case": {
+			desired:       map[string]int32{"Inventory-Admins": 0, "INVENTORY-USERS": 1},
+			opts:          []EnsureAppGroupsOpt{WithPrune(true)},
+			wantUnchanged: 2,
+			wantGroups:    []string{"g1", "g2"},
+			wantMutations: []string{},
+		},
+		"prune everything": {
+			desired:       map[string]int32{},
+			opts:          []EnsureAppGroupsOpt{WithPrune(true)},
+			wantUnassign:  []AppGroupChange{{GroupID: "g1", GroupName: "inventory-admins", Priority: 0}, {GroupID: "g2", GroupName: "inventory-users", Priority: 1}},
+			wantGroups:    []string{},
+			wantMutations: []string{"DELETE /api/v1/apps/app1/groups/g1", "DELETE /api/v1/apps/app1/groups/g2"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, server := newAppsServer(t)
+
+			plan, err := client.EnsureAppGroups(context.Background(), "app1", test.desired, test.opts...)
+			require.NoError(t, err)
+
+			assert.Equal(t, "app1", plan.AppID)
+			assert.ElementsMatch(t, test.wantAssign, plan.Assign)
+			assert.ElementsMatch(t, test.wantUpdate, plan.Update)
+			assert.ElementsMatch(t, test.wantUnassign, plan.Unassign)
+			assert.Equal(t, test.wantUnchanged, plan.Unchanged)
+			assert.Equal(t, test.wantGroups, server.AppGroups("app1"))
+			assert.Equal(t, test.wantMutations, mutations(server))
+		})
+	}
+}
+
+func TestEnsureAppGroupsErrors(t *testing.T) {
+	client, server := newAppsServer(t)
+	ctx := context.Background()
+
+	_, err := client.EnsureAppGroups(ctx, "app1", map[string]int32{"does-not-exist": 0}, WithPrune(true))
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	_, err = client.EnsureAppGroups(ctx, "app1", map[string]int32{"auditors": 0, "Auditors": 1})
+	assert.ErrorContains(t, err, "desired more than once")
+	assert.Empty(t, mutations(server))
+
+	server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/apps/app1/groups/g1", Status: http.StatusInternalServerError})
+	plan, err := client.EnsureAppGroups(ctx, "app1", map[string]int32{"auditors": 0, "inventory-admins": 1})
+	assert.Error(t, err)
+	require.NotNil(t, plan, "the plan is returned when applying it fails")
+	assert.Len(t, plan.Update, 1)
+	assert.Equal(t, []string{"g3", "g1", "g2"}, server.AppGroups("app1"))
+}
+
+func TestAppGroupPlanString(t *testing.T) {
+	plan := &AppGroupPlan{
+		AppID:     "app1",
+		Assign:    []AppGroupChange{{GroupID: "g3", GroupName: "auditors", Priority: 0}},
+		Update:    []AppGroupChange{{GroupID: "g1", GroupName: "inventory-admins", Priority: 1, PreviousPriority: okta.PtrInt32(0)}},
+		Unassign:  []AppGroupChange{{GroupID: "g2", GroupName: "inventory-users", Priority: 1}},
+		Unchanged: 2,
+		DryRun:    true,
+	}
+
+	assert.Equal(t, `application app1:
+  - unassign group "inventory-users" (g2)
+  + assign group "auditors" (g3) with priority 0
+  ~ update group "inventory-admins" (g1) priority 0 -> 1
+  2 groups unchanged
+`, plan.String())
+
+	assert.Equal(t, "application app1: no changes, 2 groups unchanged\n", (&AppGroupPlan{AppID: "app1", Unchanged: 2}).String())
+}
@@ -0,0 +1,290 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Redacted replaces the values of sensitive fields in audited request bodies.
+const Redacted = "REDACTED"
+
+// defaultRedactedFields are the request body fields that hold credentials, matched case insensitively at any depth.
+var defaultRedactedFields = []string{
+	"password", "answer", "secret", "client_secret", "client_assertion", "assertion",
+	"token", "access_token", "refresh_token", "id_token", "privateKey", "private_key",
+}
+
+// AuditRequest describes an Okta API request passed to an AuditHook.
+type AuditRequest struct {
+	Method string
+	// Path is the request's URL path, e.g. /api/v1/groups/00g1/users/00u1
+	Path  string
+	Query string
+	// Body is the request body with sensitive fields replaced with Redacted, empty when there is no body.
+	// Bodies that are not JSON or form encoded are summarized by their size.
+	Body string
+}
+
+// AuditResponse describes the outcome of an Okta API request passed to an AuditHook.
+type AuditResponse struct {
+	// StatusCode is 0 when Err is set
+	StatusCode int
+	Latency    time.Duration
+	// RequestID is the X-Okta-Request-Id header, quoted when raising support cases with Okta
+	RequestID string
+	// Err is the error returned when no response was received, e.g. a network failure or canceled context
+	Err error
+}
+
+// AuditHook observes every request the client sends to Okta, including retries and access token requests.
+// Hooks are called synchronously so they must not block for long.
+type AuditHook interface {
+	BeforeRequest(ctx context.Context, req AuditRequest)
+	AfterRequest(ctx context.Context, req AuditRequest, resp AuditResponse)
+}
+
+// WithAuditHook calls hook before and after every request the client sends, see NewLogAuditHook.
+// It may be passed more than once, hooks are called in the order they are added.
+func WithAuditHook(hook AuditHook) ClientOpt {
+	return func(o *clientOptions) {
+		o.auditHooks = append(o.auditHooks, hook)
+	}
+}
+
+// WithAuditRedactedFields adds request body fields, matched case insensitively at any depth,
+// whose values are replaced with Redacted before they are passed to audit hooks.
+func WithAuditRedactedFields(fields ...string) ClientOpt {
+	return func(o *clientOptions) {
+		o.auditRedactedFields = append(o.auditRedactedFields, fields...)
+	}
+}
+
+// auditTransport is an http.RoundTripper that reports each request and its outcome to the audit hooks.
+type auditTransport struct {
+	base   http.RoundTripper
+	hooks  []AuditHook
+	redact map[string]bool
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// newAuditTransport wraps the base transport with the audit hooks, or returns it unchanged when there are none.
+func newAuditTransport(o *clientOptions) http.RoundTripper {
+	if len(o.auditHooks) == 0 {
+		return o.baseTransport
+	}
+
+	redact := map[string]bool{}
+	for _, field := range slices.Concat(defaultRedactedFields, o.auditRedactedFields) {
+		redact[strings.ToLower(field)] = true
+	}
+
+	return &auditTransport{
+		base:   o.baseTransport,
+		hooks:  slices.Clone(o.auditHooks),
+		redact: redact,
+		now:    time.Now,
+	}
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	audit := AuditRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  req.URL.RawQuery,
+		Body:   t.requestBody(req),
+	}
+
+	for _, hook := range t.hooks {
+		hook.BeforeRequest(ctx, audit)
+	}
+
+	start := t.now()
+	resp, err := t.base.RoundTrip(req)
+
+	result := AuditResponse{Latency: t.now().Sub(start), Err: err}
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+		result.RequestID = resp.Header.Get("X-Okta-Request-Id")
+	}
+	for _, hook := range t.hooks {
+		hook.AfterRequest(ctx, audit, result)
+	}
+
+	return resp, err
+}
+
+// requestBody returns the redacted request body. The body is read from a copy so the request is not modified,
+// bodies that cannot be copied are only described.
+func (t *auditTransport) requestBody(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+	if req.GetBody == nil {
+		return "[unreadable body]"
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "[unreadable body]"
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "[unreadable body]"
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var value any
+		if err := json.Unmarshal(data, &value); err != nil {
+			break
+		}
+		redacted, err := json.Marshal(t.redactJSON(value))
+		if err != nil {
+			break
+		}
+		return string(redacted)
+	case "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			break
+		}
+		for key := range values {
+			if t.redact[strings.ToLower(key)] {
+				values[key] = []string{Redacted}
+			}
+		}
+		return values.Encode()
+	}
+
+	return fmt.Sprintf("[%d bytes]", len(data))
+}
+
+// redactJSON replaces the values of redacted fields in a decoded JSON value.
+func (t *auditTransport) redactJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if t.redact[strings.ToLower(key)] {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = t.redactJSON(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = t.redactJSON(item)
+		}
+	}
+	return value
+}
+
+// logAuditHook is the AuditHook returned by NewLogAuditHook.
+type logAuditHook struct {
+	logger log.FieldLogger
+	reads  bool
+}
+
+type LogAuditHookOpt func(*logAuditHook)
+
+// WithAuditLogger sets the logger audit entries are written to, default: the logrus standard logger.
+func WithAuditLogger(logger log.FieldLogger) LogAuditHookOpt {
+	return func(h *logAuditHook) {
+		h.logger = logger
+	}
+}
+
+// WithAuditReads also logs requests that do not modify the org, GET, HEAD and OPTIONS requests
+// and access token requests, which are skipped by default.
+func WithAuditReads(reads bool) LogAuditHookOpt {
+	return func(h *logAuditHook) {
+		h.reads = reads
+	}
+}
+
+// NewLogAuditHook returns an AuditHook that logs each mutating request with logrus once it completes,
+// at info level for successful responses and warning level for errors. Requests are logged at debug level
+// before they are sent so a request that never completes can still be traced.
+/*
+
+Example usage:
+
+client, err := okta.NewClientWithOptions(orgURL, clientID, key, okta.WithAuditHook(okta.NewLogAuditHook()))
+
+// INFO okta request  method=DELETE path=/api/v1/groups/00g1/users/00u1 status=204 latency=84ms okta_request_id=XjT3...
+*/
+func NewLogAuditHook(opts ...LogAuditHookOpt) AuditHook {
+	h := &logAuditHook{logger: log.StandardLogger()}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *logAuditHook) audited(req AuditRequest) bool {
+	if h.reads {
+		return true
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	// token requests are POSTs but only read the org
+	return !strings.HasSuffix(req.Path, "/v1/token")
+}
+
+func (h *logAuditHook) fields(req AuditRequest) log.Fields {
+	fields := log.Fields{
+		"method": req.Method,
+		"path":   req.Path,
+	}
+	if req.Query != "" {
+		fields["query"] = req.Query
+	}
+	if req.Body != "" {
+		fields["body"] = req.Body
+	}
+	return fields
+}
+
+func (h *logAuditHook) BeforeRequest(_ context.Context, req AuditRequest) {
+	if h.audited(req) {
+		h.logger.WithFields(h.fields(req)).Debug("okta request started")
+	}
+}
+
+func (h *logAuditHook) AfterRequest(_ context.Context, req AuditRequest, resp AuditResponse) {
+	if !h.audited(req) {
+		return
+	}
+
+	entry := h.logger.WithFields(h.fields(req)).WithFields(log.Fields{
+		"status":          resp.StatusCode,
+		"latency":         resp.Latency.String(),
+		"okta_request_id": resp.RequestID,
+	})
+
+	switch {
+	case resp.Err != nil:
+		entry.WithError(resp.Err).Warn("okta request failed")
+	case resp.StatusCode >= http.StatusBadRequest:
+		entry.Warn("okta request")
+	default:
+		entry.Info("okta request")
+	}
+}
@@ -0,0 +1,189 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditEntry struct {
+	req  AuditRequest
+	resp *AuditResponse
+}
+
+// recordingAuditHook records the requests it observes.
+type recordingAuditHook struct {
+	mu      sync.Mutex
+	entries []auditEntry
+}
+
+func (h *recordingAuditHook) BeforeRequest(_ context.Context, req AuditRequest) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, auditEntry{req: req})
+}
+
+func (h *recordingAuditHook) AfterRequest(_ context.Context, req AuditRequest, resp AuditResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, auditEntry{req: req, resp: &resp})
+}
+
+// completed returns the requests that received a response, excluding access token requests.
+func (h *recordingAuditHook) completed() []auditEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	entries := []auditEntry{}
+	for _, entry := range h.entries {
+		if entry.resp != nil && strings.HasPrefix(entry.req.Path, "/api/") {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func newAuditServer(t *testing.T, opts ...ClientOpt) (*Client, *oktatest.Server) {
+	return newSeededClient(t, oktatest.Fixtures{
+		Users:   []oktatest.User{{ID: "u1", Login: "alice@example.com"}},
+		Groups:  []oktatest.Group{{ID: "g1", Name: "admins"}},
+		Members: map[string][]string{"admins": {"u1"}},
+	}, opts...)
+}
+
+func TestAuditHook(t *testing.T) {
+	hook := &recordingAuditHook{}
+	client, server := newAuditServer(t, WithAuditHook(hook), WithAuditRedactedFields("email"))
+	ctx := context.Background()
+
+	request := okta.CreateUserRequest{
+		Profile: okta.UserProfile{},
+		Credentials: &okta.UserCredentials{
+			Password:         &okta.PasswordCredential{Value: okta.PtrString("hunter2")},
+			RecoveryQuestion: &okta.RecoveryQuestionCredential{Question: okta.PtrString("pet?"), Answer: okta.PtrString("rex")},
+		},
+	}
+	request.Profile.SetLogin("bob@example.com")
+	request.Profile.SetEmail("bob@example.com")
+	_, err := client.CreateUser(ctx, request, false)
+	require.NoError(t, err)
+
+	_, err = client.ListGroupUsers(ctx, "g1", WithLimit(10))
+	require.NoError(t, err)
+
+	server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/groups/g1/users/u1", Status: http.StatusForbidden})
+	_, err = client.GroupAPI.UnassignUserFromGroup(ctx, "g1", "u1").Execute()
+	require.Error(t, err)
+
+	entries := hook.completed()
+	require.Len(t, entries, 4)
+
+	// CreateUser looks the login up before creating the user
+	assert.Equal(t, http.MethodGet, entries[0].req.Method)
+	assert.Equal(t, "/api/v1/users/bob@example.com", entries[0].req.Path)
+
+	create := entries[1]
+	assert.Equal(t, http.MethodPost, create.req.Method)
+	assert.Equal(t, "/api/v1/users", create.req.Path)
+	assert.Equal(t, "activate=false", create.req.Query)
+	assert.JSONEq(t, `{
+		"profile": {"login": "bob@example.com", "email": "REDACTED"},
+		"credentials": {"password": "REDACTED", "recovery_question": {"question": "pet?", "answer": "REDACTED"}}
+	}`, create.req.Body)
+	assert.Equal(t, http.StatusOK, create.resp.StatusCode)
+	assert.NotEmpty(t, create.resp.RequestID)
+	assert.Positive(t, create.resp.Latency)
+
+	list := entries[2]
+	assert.Equal(t, "/api/v1/groups/g1/users", list.req.Path)
+	assert.Equal(t, "limit=10", list.req.Query)
+	assert.Empty(t, list.req.Body)
+
+	unassign := entries[3]
+	assert.Equal(t, http.MethodDelete, unassign.req.Method)
+	assert.Equal(t, "/api/v1/groups/g1/users/u1", unassign.req.Path)
+	assert.Equal(t, http.StatusForbidden, unassign.resp.StatusCode)
+	assert.NotEmpty(t, unassign.resp.RequestID)
+
+	// the client assertion used to request an access token is redacted
+	var token *auditEntry
+	for _, entry := range hook.entries {
+		if entry.resp != nil && strings.HasSuffix(entry.req.Path, "/v1/token") {
+			token = &entry
+		}
+	}
+	require.NotNil(t, token)
+	form, err := url.ParseQuery(token.req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, Redacted, form.Get("client_assertion"))
+	assert.Equal(t, "client_credentials", form.Get("grant_type"))
+}
+
+func TestAuditHookTransportError(t *testing.T) {
+	hook := &recordingAuditHook{}
+	client, server := newAuditServer(t, WithAuditHook(hook))
+	server.Close()
+
+	_, err := client.GroupByName(context.Background(), "admins")
+	require.Error(t, err)
+
+	require.NotEmpty(t, hook.entries)
+	last := hook.entries[len(hook.entries)-1]
+	require.NotNil(t, last.resp)
+	assert.Error(t, last.resp.Err)
+	assert.Zero(t, last.resp.StatusCode)
+}
+
+func TestLogAuditHook(t *testing.T) {
+	logger, logs := logtest.NewNullLogger()
+	logger.SetLevel(log.DebugLevel)
+
+	client, server := newAuditServer(t, WithAuditHook(NewLogAuditHook(WithAuditLogger(logger))))
+	ctx := context.Background()
+
+	// reads are not logged by default
+	_, err := client.ListGroupUsers(ctx, "g1")
+	require.NoError(t, err)
+	assert.Empty(t, logs.AllEntries())
+
+	_, err = client.GroupAPI.UnassignUserFromGroup(ctx, "g1", "u1").Execute()
+	require.NoError(t, err)
+
+	server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/groups/g1/users/u1", Status: http.StatusNotFound})
+	_, err = client.GroupAPI.AssignUserToGroup(ctx, "g1", "u1").Execute()
+	require.Error(t, err)
+
+	entries := logs.AllEntries()
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, log.DebugLevel, entries[0].Level)
+	assert.Equal(t, "okta request started", entries[0].Message)
+
+	assert.Equal(t, log.InfoLevel, entries[1].Level)
+	assert.Equal(t, "okta request", entries[1].Message)
+	assert.Equal(t, http.MethodDelete, entries[1].Data["method"])
+	assert.Equal(t, "/api/v1/groups/g1/users/u1", entries[1].Data["path"])
+	assert.Equal(t, http.StatusNoContent, entries[1].Data["status"])
+	assert.NotEmpty(t, entries[1].Data["okta_request_id"])
+	assert.Contains(t, entries[1].Data, "latency")
+
+	assert.Equal(t, log.WarnLevel, entries[3].Level)
+	assert.Equal(t, http.MethodPut, entries[3].Data["method"])
+	assert.Equal(t, http.StatusNotFound, entries[3].Data["status"])
+
+	logs.Reset()
+	client, _ = newAuditServer(t, WithAuditHook(NewLogAuditHook(WithAuditLogger(logger), WithAuditReads(true))))
+	_, err = client.ListGroupUsers(ctx, "g1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, logs.AllEntries())
+}
@@ -0,0 +1,304 @@
+package okta
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// CachedClient wraps a Client and caches the results of GroupByName and ListGroupUsers, which are
+// commonly called on hot paths such as admission webhooks. Each method has its own TTL and
+// least recently used cache, groups that are not found are cached for a shorter negative TTL,
+// and concurrent identical lookups share a single request to Okta, made with the context of the first caller.
+// All other Client methods are passed through uncached.
+/*
+
+Example usage:
+
+client, err := okta.NewClient(orgURL, clientID, key)
+cached := okta.NewCachedClient(client, okta.WithGroupTTL(10*time.Minute))
+
+group, err := cached.GroupByName(ctx, "admins")
+fmt.Println(cached.Metrics().GroupByName.Hits)
+*/
+type CachedClient struct {
+	*Client
+
+	groups  *ttlCache[okta.Group]
+	members *ttlCache[[]okta.GroupMember]
+}
+
+type cacheOptions struct {
+	// groupTTL is how long GroupByName results are cached, default: 5m
+	groupTTL time.Duration
+	// notFoundTTL is how long GroupByName caches ErrGroupNotFound, default: 30s
+	notFoundTTL time.Duration
+	// groupMembersTTL is how long ListGroupUsers results are cached, default: 1m
+	groupMembersTTL time.Duration
+	// size is the maximum number of entries kept by each method's cache, default: 1000
+	size int
+}
+
+type CacheOpt func(*cacheOptions)
+
+// WithGroupTTL sets how long GroupByName results are cached, zero disables caching.
+func WithGroupTTL(ttl time.Duration) CacheOpt {
+	return func(o *cacheOptions) {
+		o.groupTTL = ttl
+	}
+}
+
+// WithNotFoundTTL sets how long GroupByName caches groups that do not exist, zero disables negative caching.
+func WithNotFoundTTL(ttl time.Duration) CacheOpt {
+	return func(o *cacheOptions) {
+		o.notFoundTTL = ttl
+	}
+}
+
+// WithGroupMembersTTL sets how long ListGroupUsers results are cached, zero disables caching.
+func WithGroupMembersTTL(ttl time.Duration) CacheOpt {
+	return func(o *cacheOptions) {
+		o.groupMembersTTL = ttl
+	}
+}
+
+// WithCacheSize sets the maximum number of entries each method caches before evicting the least recently used.
+func WithCacheSize(size int) CacheOpt {
+	return func(o *cacheOptions) {
+		o.size = size
+	}
+}
+
+// NewCachedClient returns a CachedClient wrapping client.
+func NewCachedClient(client *Client, opts ...CacheOpt) *CachedClient {
+	options := &cacheOptions{
+		groupTTL:        5 * time.Minute,
+		notFoundTTL:     30 * time.Second,
+		groupMembersTTL: time.Minute,
+		size:            1000,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return &CachedClient{
+		Client:  client,
+		groups:  newTTLCache[okta.Group](options.size, options.groupTTL, options.notFoundTTL),
+		members: newTTLCache[[]okta.GroupMember](options.size, options.groupMembersTTL, 0),
+	}
+}
+
+// GroupByName returns the cached group named groupName, looking it up with Client.GroupByName on a miss.
+func (c *CachedClient) GroupByName(ctx context.Context, groupName string) (okta.Group, error) {
+	return c.groups.get(ctx, groupName, func(ctx context.Context) (okta.Group, error) {
+		return c.Client.GroupByName(ctx, groupName)
+	})
+}
+
+// ListGroupUsers returns the cached members of the group, listing them with Client.ListGroupUsers on a miss.
+// Calls with options bypass the cache since the options may change the result.
+func (c *CachedClient) ListGroupUsers(ctx context.Context, groupId string, opts ...ListGroupUsersOpt) ([]okta.GroupMember, error) {
+	if len(opts) > 0 {
+		return c.Client.ListGroupUsers(ctx, groupId, opts...)
+	}
+
+	members, err := c.members.get(ctx, groupId, func(ctx context.Context) ([]okta.GroupMember, error) {
+		return c.Client.ListGroupUsers(ctx, groupId)
+	})
+	// callers own the returned slice so they must not be able to modify the cached one
+	return slices.Clone(members), err
+}
+
+// Purge removes all cached entries.
+func (c *CachedClient) Purge() {
+	c.groups.purge()
+	c.members.purge()
+}
+
+// CacheStats counts the outcome of cached lookups.
+type CacheStats struct {
+	// Hits is the number of lookups served from the cache, including NegativeHits
+	Hits uint64
+	// NegativeHits is the number of lookups served a cached not found error
+	NegativeHits uint64
+	// Misses is the number of lookups that called Okta
+	Misses uint64
+	// Shared is the number of lookups that waited on an identical in flight lookup instead of calling Okta
+	Shared uint64
+	// Evictions is the number of entries removed to keep the cache within its size
+	Evictions uint64
+}
+
+// CacheMetrics holds the statistics of each cached method.
+type CacheMetrics struct {
+	GroupByName    CacheStats
+	ListGroupUsers CacheStats
+}
+
+// Metrics returns the current cache statistics.
+func (c *CachedClient) Metrics() CacheMetrics {
+	return CacheMetrics{
+		GroupByName:    c.groups.stats(),
+		ListGroupUsers: c.members.stats(),
+	}
+}
+
+// ttlCache is a size bounded LRU cache whose entries expire after a TTL. Results for the same key
+// that are requested while a lookup is in flight wait for and share that lookup's result.
+type ttlCache[V any] struct {
+	size        int
+	ttl         time.Duration
+	notFoundTTL time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	lru      *list.List
+	inflight map[string]*cacheCall[V]
+	counters CacheStats
+	// generation is incremented by purge so that fetches started before it do not cache their results
+	generation uint64
+}
+
+type cacheEntry[V any] struct {
+	key     string
+	value   V
+	err     error
+	expires time.Time
+}
+
+type cacheCall[V any] struct {
+	done       chan struct{}
+	generation uint64
+	value      V
+	err        error
+}
+
+func newTTLCache[V any](size int, ttl, notFoundTTL time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{
+		size:        size,
+		ttl:         ttl,
+		notFoundTTL: notFoundTTL,
+		now:         time.Now,
+		entries:     map[string]*list.Element{},
+		lru:         list.New(),
+		inflight:    map[string]*cacheCall[V]{},
+	}
+}
+
+// get returns the cached result for key or calls fetch to load it. Successful results are cached for
+// the TTL and ErrGroupNotFound for the not found TTL, other errors are never cached.
+// Concurrent callers for the same key share a single fetch, which runs without ctx's cancellation so that
+// one caller giving up does not fail the others. Each caller stops waiting when its own ctx is done.
+func (c *ttlCache[V]) get(ctx context.Context, key string, fetch func(context.Context) (V, error)) (V, error) {
+	c.mu.Lock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry[V])
+		if c.now().Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.counters.Hits++
+			if entry.err != nil {
+				c.counters.NegativeHits++
+			}
+			c.mu.Unlock()
+			return entry.value, entry.err
+		}
+		c.remove(elem)
+	}
+
+	call, ok := c.inflight[key]
+	if ok {
+		c.counters.Shared++
+	} else {
+		call = &cacheCall[V]{done: make(chan struct{}), generation: c.generation}
+		c.inflight[key] = call
+		c.counters.Misses++
+		go c.fetch(context.WithoutCancel(ctx), key, call, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	case <-call.done:
+		return call.value, call.err
+	}
+}
+
+// fetch loads the result of call and caches it. The call is completed and removed from the in-flight calls
+// even if fetch panics, in which case the waiting callers receive an error.
+func (c *ttlCache[V]) fetch(ctx context.Context, key string, call *cacheCall[V], fetch func(context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("failed to load %q: panic: %v", key, r)
+		}
+
+		c.mu.Lock()
+		if c.inflight[key] == call {
+			delete(c.inflight, key)
+		}
+		switch {
+		case call.generation != c.generation:
+			// the cache was purged while the result was loading
+		case call.err == nil:
+			c.add(key, call.value, nil, c.ttl)
+		case errors.Is(call.err, ErrGroupNotFound):
+			c.add(key, call.value, call.err, c.notFoundTTL)
+		}
+		c.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.value, call.err = fetch(ctx)
+}
+
+// add caches the result, evicting the least recently used entries when the cache is full. c.mu must be held.
+func (c *ttlCache[V]) add(key string, value V, err error, ttl time.Duration) {
+	if ttl <= 0 || c.size <= 0 {
+		return
+	}
+
+	entry := &cacheEntry[V]{key: key, value: value, err: err, expires: c.now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+		c.counters.Evictions++
+	}
+}
+
+// remove deletes the entry. c.mu must be held.
+func (c *ttlCache[V]) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry[V]).key)
+}
+
+// purge removes all entries. Lookups in flight still complete for their callers but their results are not cached,
+// and later lookups do not wait for them.
+func (c *ttlCache[V]) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+	c.inflight = map[string]*cacheCall[V]{}
+	c.generation++
+}
+
+func (c *ttlCache[V]) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counters
+}
@@ -0,0 +1,246 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCacheServer(t *testing.T, opts ...CacheOpt) (*CachedClient, *oktatest.Server) {
+	client, server := newSeededClient(t, oktatest.Fixtures{
+		Users:   []oktatest.User{{ID: "u1", Login: "alice@example.com"}, {ID: "u2", Login: "bob@example.com"}},
+		Groups:  []oktatest.Group{{ID: "g1", Name: "admins"}, {ID: "g2", Name: "developers"}, {ID: "g3", Name: "viewers"}},
+		Members: map[string][]string{"admins": {"u1", "u2"}},
+	})
+
+	return NewCachedClient(client, opts...), server
+}
+
+// countRequests returns the number of requests the server received with the prefix.
+func countRequests(server *oktatest.Server, prefix string) int {
+	count := 0
+	for _, r := range server.Requests() {
+		if strings.HasPrefix(r, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestCachedClientGroupByName(t *testing.T) {
+	cached, server := newCacheServer(t, WithGroupTTL(time.Minute))
+	ctx := context.Background()
+
+	now := time.Now()
+	cached.groups.now = func() time.Time { return now }
+
+	for range 3 {
+		group, err := cached.GroupByName(ctx, "admins")
+		require.NoError(t, err)
+		assert.Equal(t, "g1", group.GetId())
+	}
+	assert.Equal(t, 1, countRequests(server, "GET /api/v1/groups"))
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 1}, cached.Metrics().GroupByName)
+
+	now = now.Add(time.Minute)
+	_, err := cached.GroupByName(ctx, "admins")
+	require.NoError(t, err)
+	assert.Equal(t, 2, countRequests(server, "GET /api/v1/groups"))
+
+	cached.Purge()
+	_, err = cached.GroupByName(ctx, "admins")
+	require.NoError(t, err)
+	assert.Equal(t, 3, countRequests(server, "GET /api/v1/groups"))
+}
+
+func TestCachedClientNotFound(t *testing.T) {
+	cached, server := newCacheServer(t, WithNotFoundTTL(10*time.Second))
+	ctx := context.Background()
+
+	now := time.Now()
+	cached.groups.now = func() time.Time { return now }
+
+	for range 2 {
+		_, err := cached.GroupByName(ctx, "missing")
+		assert.ErrorIs(t, err, ErrGroupNotFound)
+		assert.EqualError(t, err, `unable to find okta group "missing"`)
+	}
+	assert.Equal(t, 1, countRequests(server, "GET /api/v1/groups"))
+	assert.Equal(t, CacheStats{Hits: 1, NegativeHits: 1, Misses: 1}, cached.Metrics().GroupByName)
+
+	now = now.Add(10 * time.Second)
+	server.AddGroup(oktatest.Group{ID: "g4", Name: "missing"})
+	group, err := cached.GroupByName(ctx, "missing")
+	require.NoError(t, err)
+	assert.Equal(t, "g4", group.GetId())
+}
+
+func TestCachedClientErrorsNotCached(t *testing.T) {
+	cached, server := newCacheServer(t)
+	ctx := context.Background()
+
+	server.InjectFault(oktatest.Fault{Status: http.StatusInternalServerError, Times: 1})
+	_, err := cached.GroupByName(ctx, "admins")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrGroupNotFound)
+
+	_, err = cached.GroupByName(ctx, "admins")
+	require.NoError(t, err)
+	assert.Equal(t, CacheStats{Misses: 2}, cached.Metrics().GroupByName)
+}
+
+func TestCachedClientEviction(t *testing.T) {
+	cached, server := newCacheServer(t, WithCacheSize(2))
+	ctx := context.Background()
+
+	for _, name := range []string{"admins", "developers", "admins", "viewers", "admins", "developers"} {
+		_, err := cached.GroupByName(ctx, name)
+		require.NoError(t, err)
+	}
+
+	// developers was the least recently used when viewers was added
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 4, Evictions: 2}, cached.Metrics().GroupByName)
+	assert.Equal(t, 4, countRequests(server, "GET /api/v1/groups"))
+}
+
+func TestCachedClientListGroupUsers(t *testing.T) {
+	cached, server := newCacheServer(t)
+	ctx := context.Background()
+
+	members, err := cached.ListGroupUsers(ctx, "g1")
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+
+	// modifying the result must not change the cached members
+	members[0] = members[1]
+
+	members, err = cached.ListGroupUsers(ctx, "g1")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", members[0].GetId())
+
+	_, err = cached.ListGroupUsers(ctx, "g1", WithLimit(1))
+	require.NoError(t, err)
+
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, cached.Metrics().ListGroupUsers)
+	assert.Equal(t, 1+2, countRequests(server, "GET /api/v1/groups/g1/users"))
+}
+
+func TestCachedClientSharesInflightLookups(t *testing.T) {
+	release := make(chan struct{})
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"g1","profile":{"name":"admins"}}]`))
+	}))
+	defer server.Close()
+
+	cached := NewCachedClient(client)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			group, err := cached.GroupByName(context.Background(), "admins")
+			assert.NoError(t, err)
+			assert.Equal(t, "g1", group.GetId())
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		stats := cached.Metrics().GroupByName
+		return stats.Misses+stats.Shared == callers
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, CacheStats{Misses: 1, Shared: callers - 1}, cached.Metrics().GroupByName)
+}
+
+func TestCachedClientInflightCancellation(t *testing.T) {
+	release := make(chan struct{})
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"g1","profile":{"name":"admins"}}]`))
+	}))
+	defer server.Close()
+
+	cached := NewCachedClient(client)
+
+	// the first caller starts the lookup and gives up before it completes
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := cached.GroupByName(ctx, "admins")
+		first <- err
+	}()
+	require.Eventually(t, func() bool { return cached.Metrics().GroupByName.Misses == 1 }, 5*time.Second, time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() {
+		group, err := cached.GroupByName(context.Background(), "admins")
+		assert.Equal(t, "g1", group.GetId())
+		second <- err
+	}()
+	require.Eventually(t, func() bool { return cached.Metrics().GroupByName.Shared == 1 }, 5*time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	close(release)
+	assert.NoError(t, <-second)
+}
+
+func TestTTLCacheFetchPanic(t *testing.T) {
+	cache := newTTLCache[string](10, time.Minute, 0)
+	ctx := context.Background()
+
+	_, err := cache.get(ctx, "key", func(context.Context) (string, error) {
+		panic("boom")
+	})
+	assert.ErrorContains(t, err, "panic: boom")
+	assert.Empty(t, cache.inflight)
+
+	value, err := cache.get(ctx, "key", func(context.Context) (string, error) {
+		return "value", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestTTLCachePurgeDuringFetch(t *testing.T) {
+	cache := newTTLCache[string](10, time.Minute, 0)
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	stale := make(chan string, 1)
+	go func() {
+		value, _ := cache.get(ctx, "key", func(context.Context) (string, error) {
+			close(started)
+			<-release
+			return "stale", nil
+		})
+		stale <- value
+	}()
+	<-started
+
+	cache.purge()
+	close(release)
+	assert.Equal(t, "stale", <-stale, "the caller waiting on the fetch still receives its result")
+
+	value, err := cache.get(ctx, "key", func(context.Context) (string, error) {
+		return "fresh", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", value)
+}
@@ -0,0 +1,133 @@
+// Package oktacmd provides a cobra command tree for querying Okta with the providers/okta Client,
+// for mounting in any binary that needs to inspect groups and users.
+package oktacmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kanopy-platform/go-library/cli"
+	"github.com/kanopy-platform/go-library/providers/okta"
+	"github.com/spf13/cobra"
+)
+
+// DefaultEnvPrefix is the prefix of the environment variables the commands are configured with, e.g. OKTA_ORG_URL.
+const DefaultEnvPrefix = "OKTA"
+
+// Config is the configuration of the commands. Each field is set by a flag or, when the flag is not passed,
+// by the environment variable of the same name in upper snake case with the env prefix, e.g. OKTA_CLIENT_ID.
+type Config struct {
+	OrgURL   string `split_words:"true"`
+	ClientID string `split_words:"true"`
+	// PrivateKey is a PEM encoded private key or a path to one
+	PrivateKey string `split_words:"true"`
+	KeyID      string `split_words:"true"`
+	// Output is the output format, one of table, json or yaml
+	Output string
+}
+
+type commands struct {
+	config    Config
+	envPrefix string
+	client    *okta.Client
+	clientOpt []okta.ClientOpt
+}
+
+type Opt func(*commands)
+
+// WithEnvPrefix sets the prefix of the configuration environment variables, default: OKTA.
+func WithEnvPrefix(prefix string) Opt {
+	return func(c *commands) {
+		c.envPrefix = prefix
+	}
+}
+
+// WithClient runs the commands with client instead of creating one from the org URL, client ID and key configuration.
+func WithClient(client *okta.Client) Opt {
+	return func(c *commands) {
+		c.client = client
+	}
+}
+
+// WithClientOpts sets the options used to create the client, e.g. okta.WithMaxRetries.
+func WithClientOpts(opts ...okta.ClientOpt) Opt {
+	return func(c *commands) {
+		c.clientOpt = append(c.clientOpt, opts...)
+	}
+}
+
+// NewCommand returns the okta command and its groups and users subcommands.
+// Configuration is read in the command's PersistentPreRunE, so when mounted under a parent
+// command with its own PersistentPreRunE enable cobra.EnableTraverseRunHooks for both to run.
+/*
+
+Example usage:
+
+root := &cobra.Command{Use: "platform"}
+root.AddCommand(oktacmd.NewCommand())
+
+// platform okta groups members admins -o yaml
+err := root.Execute()
+*/
+func NewCommand(opts ...Opt) *cobra.Command {
+	c := &commands{envPrefix: DefaultEnvPrefix}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	cmd := &cobra.Command{
+		Use:          "okta",
+		Short:        "Query Okta groups and users",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := cli.EnvconfigProcessWithPflags(c.envPrefix, cmd.Flags(), &c.config); err != nil {
+				return fmt.Errorf("failed to process okta configuration: %w", err)
+			}
+			return validateOutput(c.config.Output)
+		},
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&c.config.OrgURL, "org-url", "", "Okta org URL, e.g. https://example.okta.com")
+	flags.StringVar(&c.config.ClientID, "client-id", "", "Okta API service app client ID")
+	flags.StringVar(&c.config.PrivateKey, "private-key", "", "PEM encoded private key of the service app, or a path to one")
+	flags.StringVar(&c.config.KeyID, "key-id", "", "key ID of the private key, sent with the client assertion")
+	flags.StringVarP(&c.config.Output, "output", "o", OutputTable, "output format, one of table, json or yaml")
+
+	cmd.AddCommand(c.groupsCommand(), c.usersCommand())
+	return cmd
+}
+
+// oktaClient returns the configured client, creating it on first use.
+func (c *commands) oktaClient() (*okta.Client, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	if c.config.OrgURL == "" || c.config.ClientID == "" || c.config.PrivateKey == "" {
+		return nil, fmt.Errorf("--org-url, --client-id and --private-key, or %[1]s_ORG_URL, %[1]s_CLIENT_ID and %[1]s_PRIVATE_KEY, are required", c.envPrefix)
+	}
+
+	opts := c.clientOpt
+	if c.config.KeyID != "" {
+		opts = append(opts, okta.WithKeyID(c.config.KeyID))
+	}
+
+	client, err := okta.NewClientWithOptions(c.config.OrgURL, c.config.ClientID, c.config.PrivateKey, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.client = client
+	return client, nil
+}
+
+// run wraps a command's implementation with the client and the command's context.
+func (c *commands) run(fn func(ctx context.Context, cmd *cobra.Command, client *okta.Client, args []string) error) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		client, err := c.oktaClient()
+		if err != nil {
+			return err
+		}
+		return fn(cmd.Context(), cmd, client, args)
+	}
+}
@@ -0,0 +1,178 @@
+package oktacmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/kanopy-platform/go-library/providers/okta"
+	oktacmd "github.com/kanopy-platform/go-library/providers/okta/cmd"
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func newServer(t *testing.T) *oktatest.Server {
+	return oktatest.NewTestServer(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com", Email: "alice@example.com", FirstName: "Alice", LastName: "Smith"},
+			{ID: "u2", Login: "bob@example.com", Email: "bob@example.com", Status: "SUSPENDED"},
+		},
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: "admins", Description: "Administrators"},
+			{ID: "g2", Name: "platform-developers"},
+			{ID: "g3", Name: "platform-operators"},
+		},
+		Members: map[string][]string{"admins": {"u1", "u2"}, "platform-developers": {"u1"}},
+	})
+}
+
+// connect returns the flags configuring the commands to use the server, followed by args.
+func connect(server *oktatest.Server, args ...string) []string {
+	return append([]string{"--org-url", server.URL, "--client-id", "client-id", "--private-key", server.PrivateKey()}, args...)
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	command := oktacmd.NewCommand(oktacmd.WithClientOpts(okta.WithMaxRetries(0)))
+
+	out := &bytes.Buffer{}
+	command.SetOut(out)
+	command.SetErr(&bytes.Buffer{})
+	command.SetArgs(args)
+	err := command.Execute()
+	return out.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	server := newServer(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		err  string
+	}{
+		{
+			name: "groups get",
+			args: []string{"groups", "get", "admins", "platform-developers"},
+			want: "ID  NAME                 DESCRIPTION     MEMBERS\n" +
+				"g1  admins               Administrators  2\n" +
+				"g2  platform-developers                  1\n",
+		},
+		{
+			name: "groups get not found",
+			args: []string{"groups", "get", "admins", "missing"},
+			want: "ID  NAME    DESCRIPTION     MEMBERS\n" +
+				"g1  admins  Administrators  2\n",
+			err: `unable to find okta groups ["missing"]`,
+		},
+		{
+			name: "groups members",
+			args: []string{"groups", "members", "admins"},
+			want: "ID  LOGIN              EMAIL              STATUS\n" +
+				"u1  alice@example.com  alice@example.com  ACTIVE\n" +
+				"u2  bob@example.com    bob@example.com    SUSPENDED\n",
+		},
+		{
+			name: "groups members not found",
+			args: []string{"groups", "members", "missing"},
+			err:  `unable to find okta group "missing"`,
+		},
+		{
+			name: "groups search",
+			args: []string{"groups", "search", `profile.name sw "platform-"`},
+			want: "ID  NAME                 DESCRIPTION  MEMBERS\n" +
+				"g2  platform-developers               1\n" +
+				"g3  platform-operators                0\n",
+		},
+		{
+			name: "users get",
+			args: []string{"users", "get", "alice@example.com", "u2"},
+			want: "ID  LOGIN              EMAIL              NAME         STATUS\n" +
+				"u1  alice@example.com  alice@example.com  Alice Smith  ACTIVE\n" +
+				"u2  bob@example.com    bob@example.com                 SUSPENDED\n",
+		},
+		{
+			name: "users get not found",
+			args: []string{"users", "get", "carol@example.com"},
+			err:  `unable to find okta user "carol@example.com"`,
+		},
+		{
+			name: "invalid output",
+			args: []string{"users", "get", "u1", "-o", "csv"},
+			err:  `invalid output format "csv", must be one of table, json, yaml`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := execute(t, connect(server, test.args...)...)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.want, out)
+		})
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	server := newServer(t)
+
+	out, err := execute(t, connect(server, "groups", "members", "platform-developers", "-o", "json")...)
+	require.NoError(t, err)
+	members := []map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(out), &members))
+	require.Len(t, members, 1)
+	assert.Equal(t, "u1", members[0]["id"])
+	assert.Equal(t, "alice@example.com", members[0]["profile"].(map[string]any)["login"])
+
+	out, err = execute(t, connect(server, "groups", "get", "admins", "--output", "yaml")...)
+	require.NoError(t, err)
+	groups := []map[string]any{}
+	require.NoError(t, yaml.Unmarshal([]byte(out), &groups))
+	require.Len(t, groups, 1)
+	assert.Equal(t, "g1", groups[0]["id"])
+	assert.Equal(t, "admins", groups[0]["profile"].(map[string]any)["name"])
+}
+
+func TestConfig(t *testing.T) {
+	server := newServer(t)
+
+	// flags take precedence over the environment
+	t.Setenv("OKTA_ORG_URL", server.URL)
+	t.Setenv("OKTA_CLIENT_ID", "client-id")
+	t.Setenv("OKTA_PRIVATE_KEY", server.PrivateKey())
+	t.Setenv("OKTA_OUTPUT", "json")
+
+	out, err := execute(t, "users", "get", "u1", "-o", "table")
+	require.NoError(t, err)
+	assert.Contains(t, out, "alice@example.com  Alice Smith")
+
+	out, err = execute(t, "users", "get", "u1")
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(out)), out)
+
+	t.Setenv("OKTA_ORG_URL", "")
+	_, err = execute(t, "users", "get", "u1")
+	assert.EqualError(t, err, "--org-url, --client-id and --private-key, or OKTA_ORG_URL, OKTA_CLIENT_ID and OKTA_PRIVATE_KEY, are required")
+}
+
+func TestWithEnvPrefix(t *testing.T) {
+	server := newServer(t)
+	client, err := okta.NewClient(server.URL, "client-id", server.PrivateKey())
+	require.NoError(t, err)
+
+	t.Setenv("PLATFORM_OKTA_OUTPUT", "yaml")
+
+	command := oktacmd.NewCommand(oktacmd.WithEnvPrefix("PLATFORM_OKTA"), oktacmd.WithClient(client))
+	out := &bytes.Buffer{}
+	command.SetOut(out)
+	command.SetArgs([]string{"users", "get", "bob@example.com"})
+	require.NoError(t, command.Execute())
+	assert.Contains(t, out.String(), "login: bob@example.com")
+}
@@ -0,0 +1,107 @@
+package oktacmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kanopy-platform/go-library/providers/okta"
+	oktasdk "github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/spf13/cobra"
+)
+
+// searchExpr is an Okta search expression passed verbatim from the command line.
+type searchExpr string
+
+func (e searchExpr) String() string {
+	return string(e)
+}
+
+func (c *commands) groupsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "groups",
+		Short: "Query Okta groups",
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "get NAME...",
+			Short: "Get groups by name",
+			Args:  cobra.MinimumNArgs(1),
+			RunE:  c.run(c.getGroups),
+		},
+		&cobra.Command{
+			Use:   "members NAME",
+			Short: "List the members of a group",
+			Args:  cobra.ExactArgs(1),
+			RunE:  c.run(c.groupMembers),
+		},
+		&cobra.Command{
+			Use:     "search EXPRESSION",
+			Short:   "Search groups with an Okta search expression",
+			Example: `  okta groups search 'profile.name sw "platform-"'`,
+			Args:    cobra.ExactArgs(1),
+			RunE:    c.run(c.searchGroups),
+		},
+	)
+
+	return cmd
+}
+
+func (c *commands) getGroups(ctx context.Context, cmd *cobra.Command, client *okta.Client, args []string) error {
+	groups, err := client.GroupsByName(ctx, args, 20)
+	// groups that were found are written before reporting the names that were not
+	var notFound *okta.GroupsNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return err
+	}
+
+	if err := write(cmd.OutOrStdout(), c.config.Output, groups, groupsTable(groups)); err != nil {
+		return err
+	}
+	return err
+}
+
+func (c *commands) groupMembers(ctx context.Context, cmd *cobra.Command, client *okta.Client, args []string) error {
+	group, err := client.GroupByName(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	members, err := client.ListGroupUsers(ctx, group.GetId())
+	if err != nil {
+		return err
+	}
+
+	t := table{headers: []string{"ID", "LOGIN", "EMAIL", "STATUS"}}
+	for _, member := range members {
+		profile := member.GetProfile()
+		t.rows = append(t.rows, []string{member.GetId(), profile.GetLogin(), profile.GetEmail(), member.GetStatus()})
+	}
+	return write(cmd.OutOrStdout(), c.config.Output, members, t)
+}
+
+func (c *commands) searchGroups(ctx context.Context, cmd *cobra.Command, client *okta.Client, args []string) error {
+	groups := []oktasdk.Group{}
+	for group, err := range client.SearchGroups(ctx, searchExpr(args[0]), okta.WithExpand("stats")) {
+		if err != nil {
+			return err
+		}
+		groups = append(groups, group)
+	}
+
+	return write(cmd.OutOrStdout(), c.config.Output, groups, groupsTable(groups))
+}
+
+func groupsTable(groups []oktasdk.Group) table {
+	t := table{headers: []string{"ID", "NAME", "DESCRIPTION", "MEMBERS"}}
+	for _, group := range groups {
+		profile := group.GetProfile()
+		members := ""
+		if count, ok := group.Embedded["stats"]["usersCount"]; ok {
+			members = fmt.Sprint(count)
+		}
+		t.rows = append(t.rows, []string{group.GetId(), profile.GetName(), profile.GetDescription(), members})
+	}
+	return t
+}
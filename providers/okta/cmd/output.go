@@ -0,0 +1,64 @@
+package oktacmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+var outputs = []string{OutputTable, OutputJSON, OutputYAML}
+
+func validateOutput(output string) error {
+	if !slices.Contains(outputs, output) {
+		return fmt.Errorf("invalid output format %q, must be one of %s", output, strings.Join(outputs, ", "))
+	}
+	return nil
+}
+
+// table is the tabular rendering of a command's result.
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// write renders the result as a table, or v as JSON or YAML. JSON and YAML output the full
+// Okta resources, while the table only has the columns useful at a glance.
+func write(w io.Writer, output string, v any, t table) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to write json output: %w", err)
+		}
+	case OutputYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal yaml output: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write yaml output: %w", err)
+		}
+	default:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("failed to write table output: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package oktacmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/kanopy-platform/go-library/providers/okta"
+	oktasdk "github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/spf13/cobra"
+)
+
+func (c *commands) usersCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Query Okta users",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get ID_OR_LOGIN...",
+		Short: "Get users by id or login",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  c.run(c.getUsers),
+	})
+
+	return cmd
+}
+
+func (c *commands) getUsers(ctx context.Context, cmd *cobra.Command, client *okta.Client, args []string) error {
+	users := []*oktasdk.UserGetSingleton{}
+	for _, idOrLogin := range args {
+		user, err := client.User(ctx, idOrLogin)
+		if err != nil {
+			return err
+		}
+		users = append(users, user)
+	}
+
+	t := table{headers: []string{"ID", "LOGIN", "EMAIL", "NAME", "STATUS"}}
+	for _, user := range users {
+		profile := user.GetProfile()
+		name := strings.TrimSpace(profile.GetFirstName() + " " + profile.GetLastName())
+		t.rows = append(t.rows, []string{user.GetId(), profile.GetLogin(), profile.GetEmail(), name, user.GetStatus()})
+	}
+	return write(cmd.OutOrStdout(), c.config.Output, users, t)
+}
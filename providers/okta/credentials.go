@@ -0,0 +1,248 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	josev3 "github.com/go-jose/go-jose/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// CredentialSource loads the private key used to sign client assertions.
+// The returned bytes may be a PEM encoded private key, a JWK or a JWK Set.
+type CredentialSource interface {
+	Load(ctx context.Context) ([]byte, error)
+}
+
+// CredentialSourceFunc adapts a function, e.g. one reading from a secrets manager, to a CredentialSource.
+type CredentialSourceFunc func(ctx context.Context) ([]byte, error)
+
+func (f CredentialSourceFunc) Load(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+// CredentialWatcher is implemented by credential sources that can report when their key may have changed.
+type CredentialWatcher interface {
+	// Watch calls changed whenever the key may have changed, until ctx is done or watching fails.
+	Watch(ctx context.Context, changed func()) error
+}
+
+// credentialWatchDelay is how long a file source waits for changes to settle before reporting them,
+// so a key that is being written is not loaded half way through.
+const credentialWatchDelay = 100 * time.Millisecond
+
+type fileCredentialSource struct {
+	path string
+}
+
+// FileCredentialSource reads the key from path. The source is a CredentialWatcher that watches the file's
+// directory, so keys mounted from rotating secrets (e.g. a Kubernetes Secret volume) are picked up by
+// Client.WatchCredentials without a restart.
+func FileCredentialSource(path string) CredentialSource {
+	return &fileCredentialSource{path: path}
+}
+
+func (s *fileCredentialSource) Load(_ context.Context) ([]byte, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read okta private key file: %w", err)
+	}
+	return data, nil
+}
+
+// Watch watches the directory rather than the file since secret volumes replace files by swapping symlinks.
+func (s *fileCredentialSource) Watch(ctx context.Context, changed func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch okta private key file: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(s.path)); err != nil {
+		return fmt.Errorf("failed to watch okta private key file: %w", err)
+	}
+
+	settle := time.NewTimer(credentialWatchDelay)
+	settle.Stop()
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			settle.Reset(credentialWatchDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("failed to watch okta private key file: %w", err)
+		case <-settle.C:
+			changed()
+		}
+	}
+}
+
+// EnvCredentialSource reads the key from the environment variable name.
+func EnvCredentialSource(name string) CredentialSource {
+	return CredentialSourceFunc(func(_ context.Context) ([]byte, error) {
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("environment variable %q is not set", name)
+		}
+		return []byte(value), nil
+	})
+}
+
+// NewClientFromSource creates a Client whose private key is loaded from source.
+// The key is loaded once, use Client.WatchCredentials or Client.ReloadCredentials to pick up a rotated key.
+/*
+
+Example usage:
+
+client, err := okta.NewClientFromSource(orgURL, clientID, okta.FileCredentialSource("/etc/okta/key.pem"),
+	okta.WithScopes("okta.groups.read"),
+)
+if err != nil {
+	return err
+}
+
+go func() {
+	err := client.WatchCredentials(ctx, func(err error) {
+		log.Errorf("rotated okta private key rejected: %s", err)
+	})
+	...
+}()
+*/
+func NewClientFromSource(orgURL string, clientID string, source CredentialSource, opts ...ClientOpt) (*Client, error) {
+	options := newClientOptions(opts...)
+
+	signer := &rotatingSigner{
+		source: source,
+		keyID:  options.keyID,
+	}
+
+	if err := signer.reload(context.Background()); err != nil {
+		return nil, err
+	}
+
+	client, err := newClient(orgURL, clientID, signer, options)
+	if err != nil {
+		return nil, err
+	}
+	client.credentials = signer
+
+	return client, nil
+}
+
+// ReloadCredentials loads the key from the client's CredentialSource and uses it for client assertions
+// if it is valid. A key that cannot be loaded or parsed is returned as an error and the current key is kept.
+// The new key is used from the next access token the client requests.
+func (c *Client) ReloadCredentials(ctx context.Context) error {
+	if c.credentials == nil {
+		return errNoCredentialSource
+	}
+	return c.credentials.reload(ctx)
+}
+
+// WatchCredentials reloads the key with ReloadCredentials whenever the client's CredentialSource reports a change,
+// until ctx is done. Keys that fail to reload are passed to onError, which may be nil, and the current key is kept.
+// An error is returned when the source is not a CredentialWatcher or watching it fails.
+func (c *Client) WatchCredentials(ctx context.Context, onError func(error)) error {
+	if c.credentials == nil {
+		return errNoCredentialSource
+	}
+
+	watcher, ok := c.credentials.source.(CredentialWatcher)
+	if !ok {
+		return fmt.Errorf("okta credential source %T cannot be watched", c.credentials.source)
+	}
+
+	return watcher.Watch(ctx, func() {
+		if err := c.ReloadCredentials(ctx); err != nil {
+			log.Warnf("failed to reload okta private key, using previous key: %s", err)
+			if onError != nil {
+				onError(err)
+			}
+		}
+	})
+}
+
+var errNoCredentialSource = errors.New("okta client was not created from a credential source")
+
+// rotatingSigner is a josev3.Signer whose key can be replaced while the client is in use.
+type rotatingSigner struct {
+	source CredentialSource
+	keyID  string
+
+	mu      sync.RWMutex
+	current []byte
+	signer  josev3.Signer
+}
+
+// reload loads the source's key and replaces the signer when the key has changed and is valid.
+func (s *rotatingSigner) reload(ctx context.Context) error {
+	data, err := s.source.Load(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	unchanged := bytes.Equal(data, s.current)
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	signer, err := signerFromKey(data, s.keyID)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.signer != nil {
+		log.Info("okta private key changed, using new key for client assertions")
+	}
+	s.current = data
+	s.signer = signer
+
+	return nil
+}
+
+func (s *rotatingSigner) Sign(payload []byte) (*josev3.JSONWebSignature, error) {
+	s.mu.RLock()
+	signer := s.signer
+	s.mu.RUnlock()
+	return signer.Sign(payload)
+}
+
+func (s *rotatingSigner) Options() josev3.SignerOptions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.signer.Options()
+}
+
+// signerFromKey builds a signer from either a JWK (or JWK Set) or a PEM encoded private key.
+// Unlike NewClient, the data is never treated as a path so a misconfigured source cannot read other files.
+func signerFromKey(data []byte, kid string) (josev3.Signer, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		jwk, err := jwkFromBytes(data, kid)
+		if err != nil {
+			return nil, err
+		}
+		return signerFromJWK(jwk)
+	}
+
+	return signerFromPEMBytes(data, kid)
+}
@@ -0,0 +1,140 @@
+package okta
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertSignedBy checks that the client's current signer produces signatures verified by key.
+func assertSignedBy(t *testing.T, client *Client, key *ecdsa.PrivateKey) {
+	t.Helper()
+
+	jws, err := client.GetConfig().PrivateKeySigner.Sign([]byte("payload"))
+	require.NoError(t, err)
+	compact, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	parsed, err := jose.ParseSigned(compact, testAlgorithms)
+	require.NoError(t, err)
+	_, err = parsed.Verify(&key.PublicKey)
+	assert.NoError(t, err)
+}
+
+func TestWatchCredentials(t *testing.T) {
+	first := testECKey(t, elliptic.P256())
+	second := testECKey(t, elliptic.P384())
+
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(pkcs8PEM(t, first)), 0o600))
+
+	client, err := NewClientFromSource("https://example.okta.com", "client", FileCredentialSource(path))
+	require.NoError(t, err)
+	assertSignedBy(t, client, first)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rejected := make(chan error, 10)
+	watched := make(chan error, 1)
+	go func() {
+		watched <- client.WatchCredentials(ctx, func(err error) { rejected <- err })
+	}()
+
+	// rotate to a JWK with a different key type, rewriting it in case the watcher had not started
+	require.Eventually(t, func() bool {
+		require.NoError(t, os.WriteFile(path, jwkJSON(t, second, "second", ""), 0o600))
+		time.Sleep(2 * credentialWatchDelay)
+		return assertionHeader(t, client).Algorithm == "ES384"
+	}, 5*time.Second, time.Millisecond)
+	assertSignedBy(t, client, second)
+	assert.Equal(t, "second", assertionHeader(t, client).KeyID)
+
+	// a broken key is reported and the previous one is kept
+	require.NoError(t, os.WriteFile(path, []byte("garbage"), 0o600))
+	select {
+	case err := <-rejected:
+		assert.ErrorIs(t, err, errNotPEM)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the broken key was not reported")
+	}
+	assertSignedBy(t, client, second)
+
+	cancel()
+	assert.NoError(t, <-watched)
+}
+
+func TestReloadCredentials(t *testing.T) {
+	keys := []*ecdsa.PrivateKey{testECKey(t, elliptic.P256()), testECKey(t, elliptic.P256())}
+	loads := 0
+
+	source := CredentialSourceFunc(func(ctx context.Context) ([]byte, error) {
+		if loads >= len(keys) {
+			return nil, fmt.Errorf("secret store unavailable")
+		}
+		key := keys[loads]
+		loads++
+		return []byte(pkcs8PEM(t, key)), nil
+	})
+
+	client, err := NewClientFromSource("https://example.okta.com", "client", source)
+	require.NoError(t, err)
+	// the key is not reloaded when signing
+	assertSignedBy(t, client, keys[0])
+	assertSignedBy(t, client, keys[0])
+
+	require.NoError(t, client.ReloadCredentials(context.Background()))
+	assertSignedBy(t, client, keys[1])
+
+	// the source is failing so the last loaded key is kept
+	assert.EqualError(t, client.ReloadCredentials(context.Background()), "secret store unavailable")
+	assertSignedBy(t, client, keys[1])
+
+	err = client.WatchCredentials(context.Background(), nil)
+	assert.EqualError(t, err, "okta credential source okta.CredentialSourceFunc cannot be watched")
+
+	client, err = NewClient("https://example.okta.com", "client", pkcs8PEM(t, keys[0]))
+	require.NoError(t, err)
+	assert.ErrorIs(t, client.ReloadCredentials(context.Background()), errNoCredentialSource)
+}
+
+func TestEnvCredentialSource(t *testing.T) {
+	key := testECKey(t, elliptic.P256())
+	t.Setenv("TEST_OKTA_PRIVATE_KEY", pkcs8PEM(t, key))
+
+	client, err := NewClientFromSource("https://example.okta.com", "client", EnvCredentialSource("TEST_OKTA_PRIVATE_KEY"), WithKeyID("env"))
+	require.NoError(t, err)
+	assertSignedBy(t, client, key)
+	assert.Equal(t, "env", assertionHeader(t, client).KeyID)
+
+	_, err = NewClientFromSource("https://example.okta.com", "client", EnvCredentialSource("TEST_OKTA_MISSING_KEY"))
+	assert.Error(t, err)
+}
+
+func TestNewClientFromSourceInvalidKey(t *testing.T) {
+	source := CredentialSourceFunc(func(ctx context.Context) ([]byte, error) {
+		return []byte("not a key"), nil
+	})
+
+	client, err := NewClientFromSource("https://example.okta.com", "client", source)
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
+
+func TestEnvCredentialSourceIsNotAPath(t *testing.T) {
+	key := testECKey(t, elliptic.P256())
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, []byte(pkcs8PEM(t, key)), 0o600))
+	t.Setenv("TEST_OKTA_PRIVATE_KEY", path)
+
+	client, err := NewClientFromSource("https://example.okta.com", "client", EnvCredentialSource("TEST_OKTA_PRIVATE_KEY"))
+	assert.ErrorIs(t, err, errNotPEM)
+	assert.Nil(t, client)
+}
@@ -0,0 +1,234 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+var (
+	// ErrGroupNotFound is returned when no group has the requested name or id.
+	ErrGroupNotFound = errors.New("unable to find okta group")
+	// ErrUserNotFound is returned when no user has the requested id or login.
+	ErrUserNotFound = errors.New("unable to find okta user")
+	// ErrUnauthorized matches API errors for requests Okta rejected with 401, e.g. a revoked access token.
+	ErrUnauthorized = errors.New("okta request unauthorized")
+	// ErrForbidden matches API errors for requests Okta rejected with 403, usually because the
+	// client is missing an OAuth scope or admin role.
+	ErrForbidden = errors.New("okta request forbidden")
+	// ErrRateLimited matches API errors for requests Okta rejected with 429 after the client's retries
+	// were exhausted, use errors.As with a *RateLimitError to get the reset time.
+	ErrRateLimited = errors.New("okta rate limit exceeded")
+)
+
+// APIError is an error response from the Okta API.
+// See https://developer.okta.com/docs/reference/error-codes/ for the error codes.
+/*
+
+Example usage:
+
+_, err := client.ListGroupUsers(ctx, groupID)
+
+var apiErr *okta.APIError
+if errors.As(err, &apiErr) {
+	log.Errorf("okta error %s, request id %s", apiErr.ErrorCode, apiErr.RequestID)
+}
+
+if errors.Is(err, okta.ErrUnauthorized) {
+	...
+}
+*/
+type APIError struct {
+	StatusCode int
+	// ErrorCode is Okta's error code, e.g. E0000007
+	ErrorCode    string
+	ErrorSummary string
+	// Causes are the summaries of the errorCauses, e.g. the invalid fields of a request body
+	Causes []string
+	// RequestID is the X-Okta-Request-Id of the response, which Okta support uses to find the request
+	RequestID string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("okta api error %d", e.StatusCode)
+	if e.ErrorCode != "" {
+		msg += " " + e.ErrorCode
+	}
+	if e.ErrorSummary != "" {
+		msg += ": " + e.ErrorSummary
+	}
+	if len(e.Causes) > 0 {
+		msg += " (" + strings.Join(e.Causes, "; ") + ")"
+	}
+	if e.RequestID != "" {
+		msg += ", request id " + e.RequestID
+	}
+	return msg
+}
+
+// Is matches ErrUnauthorized, ErrForbidden and ErrRateLimited by status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// RateLimitError is returned for requests that are still rate limited after the client's retries.
+type RateLimitError struct {
+	*APIError
+	// Reset is when the endpoint's rate limit resets, zero when the response did not include X-Rate-Limit-Reset
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return e.APIError.Error()
+	}
+	return fmt.Sprintf("%s, resets at %s", e.APIError.Error(), e.Reset.UTC().Format(time.RFC3339))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// apiError converts an error returned by the SDK with an error response into an *APIError, or a
+// *RateLimitError for 429 responses. Errors without a response, such as network failures, are returned unchanged,
+// except that they also match ctx's error once ctx is done.
+func apiError(ctx context.Context, resp *okta.APIResponse, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if resp != nil && resp.Response != nil && resp.StatusCode >= http.StatusMultipleChoices {
+		var body []byte
+		var openAPIErr *okta.GenericOpenAPIError
+		if errors.As(err, &openAPIErr) {
+			body = openAPIErr.Body()
+		}
+		return newAPIError(resp.StatusCode, resp.Header, body)
+	}
+
+	// the SDK returns rate limited responses as a "too many requests" error without the response
+	if recorder, ok := ctx.Value(rateLimitRecorderKey{}).(*rateLimitRecorder); ok {
+		if rateLimitErr := recorder.take(); rateLimitErr != nil {
+			return rateLimitErr
+		}
+	}
+
+	// the SDK returns transport errors as strings, restore the context error of canceled requests
+	if ctxErr := ctx.Err(); ctxErr != nil && !errors.Is(err, ctxErr) {
+		return &canceledError{err: err, ctxErr: ctxErr}
+	}
+
+	return err
+}
+
+// canceledError is an error the SDK returned for a request whose context is done, it matches the context's error.
+type canceledError struct {
+	err    error
+	ctxErr error
+}
+
+func (e *canceledError) Error() string {
+	return e.err.Error()
+}
+
+func (e *canceledError) Unwrap() []error {
+	return []error{e.err, e.ctxErr}
+}
+
+func newAPIError(status int, header http.Header, body []byte) error {
+	apiErr := &APIError{
+		StatusCode: status,
+		RequestID:  header.Get("X-Okta-Request-Id"),
+	}
+
+	var model struct {
+		ErrorCode    string `json:"errorCode"`
+		ErrorSummary string `json:"errorSummary"`
+		ErrorCauses  []struct {
+			ErrorSummary string `json:"errorSummary"`
+		} `json:"errorCauses"`
+	}
+	if json.Unmarshal(body, &model) == nil {
+		apiErr.ErrorCode = model.ErrorCode
+		apiErr.ErrorSummary = model.ErrorSummary
+		for _, cause := range model.ErrorCauses {
+			apiErr.Causes = append(apiErr.Causes, cause.ErrorSummary)
+		}
+	}
+	if apiErr.ErrorSummary == "" {
+		apiErr.ErrorSummary = http.StatusText(status)
+	}
+
+	if status == http.StatusTooManyRequests {
+		rateLimitErr := &RateLimitError{APIError: apiErr}
+		if reset, err := strconv.ParseInt(header.Get("X-Rate-Limit-Reset"), 10, 64); err == nil {
+			rateLimitErr.Reset = time.Unix(reset, 0)
+		}
+		return rateLimitErr
+	}
+
+	return apiErr
+}
+
+type rateLimitRecorderKey struct{}
+
+// rateLimitRecorder holds the last rate limited response the transport returned for requests
+// made with a context from withRateLimitRecorder.
+type rateLimitRecorder struct {
+	mu  sync.Mutex
+	err *RateLimitError
+}
+
+// withRateLimitRecorder returns a context that lets apiError report the reset time and error details
+// of rate limited requests. Requests must be built with the returned context.
+func withRateLimitRecorder(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(rateLimitRecorderKey{}).(*rateLimitRecorder); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, rateLimitRecorderKey{}, &rateLimitRecorder{})
+}
+
+// recordRateLimited saves the rate limited resp in the request context's recorder, if any.
+// The body is read and replaced so the response can still be consumed.
+func recordRateLimited(ctx context.Context, resp *http.Response) {
+	recorder, ok := ctx.Value(rateLimitRecorderKey{}).(*rateLimitRecorder)
+	if !ok {
+		return
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	rateLimitErr, _ := newAPIError(resp.StatusCode, resp.Header, body).(*RateLimitError)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.err = rateLimitErr
+}
+
+func (r *rateLimitRecorder) take() *RateLimitError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.err
+	r.err = nil
+	return err
+}
@@ -0,0 +1,102 @@
+package okta
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		sentinel  error
+		errorCode string
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, sentinel: ErrUnauthorized, errorCode: "E0000011"},
+		{name: "forbidden", status: http.StatusForbidden, sentinel: ErrForbidden, errorCode: "E0000006"},
+		{name: "rate limited", status: http.StatusTooManyRequests, sentinel: ErrRateLimited, errorCode: "E0000047"},
+		{name: "server error", status: http.StatusInternalServerError, errorCode: "E0000009"},
+	}
+
+	calls := map[string]func(*Client, context.Context) error{
+		"GroupByName": func(c *Client, ctx context.Context) error {
+			_, err := c.GroupByName(ctx, "admins")
+			return err
+		},
+		"GroupsByName": func(c *Client, ctx context.Context) error {
+			_, err := c.GroupsByName(ctx, []string{"admins"}, 10)
+			return err
+		},
+		"ListGroupUsers": func(c *Client, ctx context.Context) error {
+			_, err := c.ListGroupUsers(ctx, "g1")
+			return err
+		},
+	}
+
+	for _, test := range tests {
+		for name, call := range calls {
+			t.Run(test.name+"/"+name, func(t *testing.T) {
+				client, server := newSeededClient(t, oktatest.Fixtures{Groups: []oktatest.Group{{ID: "g1", Name: "admins"}}})
+				server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/groups", Status: test.status})
+
+				err := call(client, context.Background())
+
+				var apiErr *APIError
+				require.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, test.status, apiErr.StatusCode)
+				assert.Equal(t, test.errorCode, apiErr.ErrorCode)
+				assert.NotEmpty(t, apiErr.ErrorSummary)
+				assert.Regexp(t, `^oktatest-\d+$`, apiErr.RequestID)
+
+				for _, sentinel := range []error{ErrUnauthorized, ErrForbidden, ErrRateLimited, ErrGroupNotFound} {
+					assert.Equal(t, sentinel == test.sentinel, errors.Is(err, sentinel), "errors.Is(err, %v)", sentinel)
+				}
+
+				var rateLimitErr *RateLimitError
+				if errors.As(err, &rateLimitErr) {
+					assert.Equal(t, http.StatusTooManyRequests, test.status)
+					assert.WithinDuration(t, time.Now(), rateLimitErr.Reset, 5*time.Second)
+				}
+			})
+		}
+	}
+}
+
+func TestAPIErrorNotFound(t *testing.T) {
+	client, _ := newUsersServer(t)
+
+	_, err := client.ListGroupUsers(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "E0000007", apiErr.ErrorCode)
+
+	_, err = client.GroupsByName(context.Background(), []string{"admins", "missing"}, 10)
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := &APIError{
+		StatusCode:   http.StatusBadRequest,
+		ErrorCode:    "E0000001",
+		ErrorSummary: "Api validation failed: login",
+		Causes:       []string{"login: An object with this field already exists"},
+		RequestID:    "req-1",
+	}
+	assert.EqualError(t, err, "okta api error 400 E0000001: Api validation failed: login (login: An object with this field already exists), request id req-1")
+
+	rateLimitErr := &RateLimitError{
+		APIError: &APIError{StatusCode: http.StatusTooManyRequests},
+		Reset:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	assert.EqualError(t, rateLimitErr, "okta api error 429, resets at 2024-01-02T03:04:05Z")
+	assert.ErrorIs(t, rateLimitErr, ErrRateLimited)
+}
@@ -0,0 +1,188 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// Expr is an Okta filter or search expression, see
+// https://developer.okta.com/docs/api/#filter and https://developer.okta.com/docs/api/#search.
+// Expressions are built with the comparison functions, e.g. Eq or Sw, and combined with And, Or and Not.
+// Nested expressions are grouped with parentheses and values are quoted and escaped as needed.
+/*
+
+Example usage:
+
+expr := And(Eq("status", "ACTIVE"), Or(Sw("profile.login", "svc-"), Co("profile.email", `"quoted"`)))
+expr.String() // status eq "ACTIVE" and (profile.login sw "svc-" or profile.email co "\"quoted\"")
+*/
+type Expr interface {
+	String() string
+}
+
+// comparisonExpr compares an attribute to a value, e.g. profile.name eq "admins".
+type comparisonExpr struct {
+	attr  string
+	op    string
+	value any
+}
+
+func (e comparisonExpr) String() string {
+	if e.op == "pr" {
+		return e.attr + " pr"
+	}
+	return fmt.Sprintf("%s %s %s", e.attr, e.op, formatValue(e.value))
+}
+
+// logicalExpr joins expressions with and/or.
+type logicalExpr struct {
+	op    string
+	exprs []Expr
+}
+
+func (e logicalExpr) String() string {
+	parts := make([]string, 0, len(e.exprs))
+	for _, expr := range e.exprs {
+		parts = append(parts, group(expr, e.op))
+	}
+	return strings.Join(parts, " "+e.op+" ")
+}
+
+type notExpr struct {
+	expr Expr
+}
+
+func (e notExpr) String() string {
+	return "not (" + e.expr.String() + ")"
+}
+
+// group wraps nested logical expressions using a different operator in parentheses.
+func group(expr Expr, parentOp string) string {
+	if l, ok := expr.(logicalExpr); ok && l.op != parentOp {
+		return "(" + l.String() + ")"
+	}
+	return expr.String()
+}
+
+// formatValue renders strings and times as quoted, escaped literals and other values as-is.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return quote(v)
+	case time.Time:
+		return quote(v.UTC().Format("2006-01-02T15:04:05.000Z"))
+	case bool:
+		return strconv.FormatBool(v)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(v)
+	default:
+		return quote(fmt.Sprint(v))
+	}
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// Eq matches attributes equal to value.
+func Eq(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "eq", value: value}
+}
+
+// Ne matches attributes not equal to value.
+func Ne(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "ne", value: value}
+}
+
+// Sw matches attributes starting with value.
+func Sw(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "sw", value: value}
+}
+
+// Co matches attributes containing value.
+func Co(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "co", value: value}
+}
+
+// Ew matches attributes ending with value.
+func Ew(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "ew", value: value}
+}
+
+// Gt matches attributes greater than value.
+func Gt(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "gt", value: value}
+}
+
+// Ge matches attributes greater than or equal to value.
+func Ge(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "ge", value: value}
+}
+
+// Lt matches attributes less than value.
+func Lt(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "lt", value: value}
+}
+
+// Le matches attributes less than or equal to value.
+func Le(attr string, value any) Expr {
+	return comparisonExpr{attr: attr, op: "le", value: value}
+}
+
+// Pr matches resources where the attribute is present.
+func Pr(attr string) Expr {
+	return comparisonExpr{attr: attr, op: "pr"}
+}
+
+// And matches when all expressions match.
+func And(exprs ...Expr) Expr {
+	return logical("and", exprs)
+}
+
+// Or matches when any expression matches.
+func Or(exprs ...Expr) Expr {
+	return logical("or", exprs)
+}
+
+// Not negates the expression.
+func Not(expr Expr) Expr {
+	return notExpr{expr: expr}
+}
+
+// logical flattens nested expressions using the same operator and drops empty expressions.
+func logical(op string, exprs []Expr) Expr {
+	flat := []Expr{}
+	for _, expr := range exprs {
+		if expr == nil || expr.String() == "" {
+			continue
+		}
+		if l, ok := expr.(logicalExpr); ok && l.op == op {
+			flat = append(flat, l.exprs...)
+			continue
+		}
+		flat = append(flat, expr)
+	}
+
+	if len(flat) == 1 {
+		return flat[0]
+	}
+	return logicalExpr{op: op, exprs: flat}
+}
+
+// SearchUsers streams the users matching the search expression, it is shorthand for Users with WithSearch.
+func (c *Client) SearchUsers(ctx context.Context, expr Expr, opts ...ListOpt) iter.Seq2[okta.User, error] {
+	return c.Users(ctx, append(opts, WithSearch(expr))...)
+}
+
+// SearchGroups streams the groups matching the search expression, it is shorthand for Groups with WithSearch.
+func (c *Client) SearchGroups(ctx context.Context, expr Expr, opts ...ListOpt) iter.Seq2[okta.Group, error] {
+	return c.Groups(ctx, append(opts, WithSearch(expr))...)
+}
@@ -0,0 +1,120 @@
+package okta
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExprString(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected string
+	}{
+		{
+			name:     "eq",
+			expr:     Eq("profile.name", "admins"),
+			expected: `profile.name eq "admins"`,
+		},
+		{
+			name:     "quotes and backslashes are escaped",
+			expr:     Eq("profile.name", `say "hi" \o/`),
+			expected: `profile.name eq "say \"hi\" \\o/"`,
+		},
+		{
+			name:     "comparison operators",
+			expr:     And(Ne("status", "DEPROVISIONED"), Sw("profile.login", "svc-"), Co("profile.email", "@"), Ew("profile.email", ".com")),
+			expected: `status ne "DEPROVISIONED" and profile.login sw "svc-" and profile.email co "@" and profile.email ew ".com"`,
+		},
+		{
+			name:     "values",
+			expr:     And(Gt("count", 5), Le("ratio", 0.5), Eq("enabled", true), Lt("lastUpdated", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))),
+			expected: `count gt 5 and ratio le 0.5 and enabled eq true and lastUpdated lt "2024-01-02T03:04:05.000Z"`,
+		},
+		{
+			name:     "present",
+			expr:     Pr("profile.manager"),
+			expected: `profile.manager pr`,
+		},
+		{
+			name:     "nested expressions are grouped",
+			expr:     And(Eq("status", "ACTIVE"), Or(Sw("profile.login", "a"), Sw("profile.login", "b"))),
+			expected: `status eq "ACTIVE" and (profile.login sw "a" or profile.login sw "b")`,
+		},
+		{
+			name:     "same operator is flattened",
+			expr:     Or(Eq("id", "1"), Or(Eq("id", "2"), Eq("id", "3"))),
+			expected: `id eq "1" or id eq "2" or id eq "3"`,
+		},
+		{
+			name:     "not",
+			expr:     Not(Or(Eq("type", "APP_GROUP"), Eq("type", "BUILT_IN"))),
+			expected: `not (type eq "APP_GROUP" or type eq "BUILT_IN")`,
+		},
+		{
+			name:     "single expression is not grouped",
+			expr:     And(Or(Eq("id", "1"))),
+			expected: `id eq "1"`,
+		},
+		{
+			name:     "empty",
+			expr:     Or(),
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.expr.String())
+		})
+	}
+}
+
+func TestSearchWithExpr(t *testing.T) {
+	client, _ := newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "svc-build@example.com", Status: "ACTIVE"},
+			{ID: "u2", Login: "svc-deploy@example.com", Status: "SUSPENDED"},
+			{ID: "u3", Login: "alice@example.com", Status: "ACTIVE"},
+		},
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: `team "blue"`},
+			{ID: "g2", Name: `C:\admins`},
+			{ID: "g3", Name: "other"},
+		},
+	})
+
+	ctx := context.Background()
+
+	users := []string{}
+	for user, err := range client.SearchUsers(ctx, And(Sw("profile.login", "svc-"), Eq("status", "ACTIVE"))) {
+		require.NoError(t, err)
+		users = append(users, user.GetId())
+	}
+	assert.Equal(t, []string{"u1"}, users)
+
+	groups := []string{}
+	for group, err := range client.SearchGroups(ctx, Not(Eq("profile.name", "other"))) {
+		require.NoError(t, err)
+		groups = append(groups, group.GetId())
+	}
+	assert.ElementsMatch(t, []string{"g1", "g2"}, groups)
+
+	found, err := client.GroupsByName(ctx, []string{`team "blue"`, `C:\admins`}, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"g1", "g2"}, groupIDs(found))
+}
+
+func groupIDs(groups []okta.Group) []string {
+	ids := []string{}
+	for _, group := range groups {
+		ids = append(ids, group.GetId())
+	}
+	return ids
+}
@@ -0,0 +1,154 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// ListRequest is satisfied by any Okta SDK list request builder, such as okta.ApiListGroupUsersRequest,
+// okta.ApiListGroupsRequest, okta.ApiListApplicationsRequest or okta.ApiListLogEventsRequest.
+// R is the request type itself, After returns a copy of the request that starts at the cursor.
+type ListRequest[T, R any] interface {
+	Execute() ([]T, *okta.APIResponse, error)
+	After(string) R
+}
+
+// Pages executes the request and yields one page of results at a time, following the Link rel="next"
+// header until there are no more pages, the caller stops ranging or ctx is done.
+// Iteration ends after the first error is yielded, error responses are yielded as an *APIError.
+// Rate limited requests are only reported as a *RateLimitError when req was built with a context
+// from the client's own list methods, otherwise the SDK returns a "too many requests" error.
+//
+// Each page after the first is requested by setting the after cursor of the next link on req,
+// so every request uses the context req was built with, which should be ctx.
+/*
+
+Example usage:
+
+for page, err := range Pages(ctx, client.UserAPI.ListUsers(ctx).Limit(200)) {
+	if err != nil {
+		return err
+	}
+	process(page)
+}
+*/
+func Pages[T any, R ListRequest[T, R]](ctx context.Context, req R) iter.Seq2[[]T, error] {
+	return func(yield func([]T, error) bool) {
+		page, resp, err := req.Execute()
+		err = apiError(ctx, resp, err)
+		for {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(page, nil) {
+				return
+			}
+
+			if resp == nil || !resp.HasNextPage() {
+				return
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			cursor, linkErr := nextPageCursor(resp)
+			if linkErr != nil {
+				yield(nil, fmt.Errorf("failed to receive pagination results: %w", linkErr))
+				return
+			}
+
+			req = req.After(cursor)
+			page, resp, err = req.Execute()
+			if err = apiError(ctx, resp, err); err != nil {
+				err = fmt.Errorf("failed to receive pagination results: %w", err)
+			}
+		}
+	}
+}
+
+// nextPageCursor returns the after cursor of resp's next link.
+func nextPageCursor(resp *okta.APIResponse) (string, error) {
+	link, err := url.Parse(resp.NextPage())
+	if err != nil {
+		return "", fmt.Errorf("failed to parse next link: %w", err)
+	}
+
+	cursor := link.Query().Get("after")
+	if cursor == "" {
+		return "", fmt.Errorf("next link %q has no after cursor", resp.NextPage())
+	}
+	return cursor, nil
+}
+
+// All flattens Pages into a sequence of individual items.
+// Only one page of results is held in memory at a time and breaking out of the loop
+// stops any further pages from being requested.
+/*
+
+Example usage:
+
+for app, err := range All(ctx, client.ApplicationAPI.ListApplications(ctx)) {
+	if err != nil {
+		return err
+	}
+	...
+}
+*/
+func All[T any, R ListRequest[T, R]](ctx context.Context, req R) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range Pages(ctx, req) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range page {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GroupUsers streams the members of the group with the given id.
+func (c *Client) GroupUsers(ctx context.Context, groupId string, opts ...ListOpt) iter.Seq2[okta.GroupMember, error] {
+	ctx = withRateLimitRecorder(ctx)
+	query, err := applyListOpts(c.GroupAPI.ListGroupUsers(ctx, groupId), opts...)
+	if err != nil {
+		return errSeq[okta.GroupMember](err)
+	}
+
+	return All(ctx, query)
+}
+
+// Users streams the org's users.
+func (c *Client) Users(ctx context.Context, opts ...ListOpt) iter.Seq2[okta.User, error] {
+	ctx = withRateLimitRecorder(ctx)
+	query, err := applyListOpts(c.UserAPI.ListUsers(ctx), opts...)
+	if err != nil {
+		return errSeq[okta.User](err)
+	}
+
+	return All(ctx, query)
+}
+
+// Groups streams the org's groups.
+func (c *Client) Groups(ctx context.Context, opts ...ListOpt) iter.Seq2[okta.Group, error] {
+	ctx = withRateLimitRecorder(ctx)
+	query, err := applyListOpts(c.GroupAPI.ListGroups(ctx), opts...)
+	if err != nil {
+		return errSeq[okta.Group](err)
+	}
+
+	return All(ctx, query)
+}
@@ -0,0 +1,174 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedUsersHandler serves total group members for any group, limit members per page,
+// using the after cursor and Link headers the same way the Okta API does.
+func pagedUsersHandler(total, limit int, requests *atomic.Int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		after, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		end := min(after+limit, total)
+
+		users := []okta.GroupMember{}
+		for i := after; i < end; i++ {
+			users = append(users, okta.GroupMember{Id: okta.PtrString(fmt.Sprintf("user%d", i))})
+		}
+
+		if end < total {
+			w.Header().Add("Link", fmt.Sprintf(`<http://%s%s?after=%d&limit=%d>; rel="next"`, r.Host, r.URL.Path, end, limit))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(users)
+	}
+}
+
+func TestPages(t *testing.T) {
+	requests := &atomic.Int32{}
+	client, _ := newTestClient(t, pagedUsersHandler(5, 2, requests))
+	ctx := context.Background()
+
+	sizes := []int{}
+	for page, err := range Pages(ctx, client.GroupAPI.ListGroupUsers(ctx, "group").Limit(2)) {
+		require.NoError(t, err)
+		sizes = append(sizes, len(page))
+	}
+
+	assert.Equal(t, []int{2, 2, 1}, sizes)
+	assert.EqualValues(t, 3, requests.Load())
+}
+
+func TestAllEarlyExit(t *testing.T) {
+	requests := &atomic.Int32{}
+	client, _ := newTestClient(t, pagedUsersHandler(10, 2, requests))
+	ctx := context.Background()
+
+	ids := []string{}
+	for user, err := range client.GroupUsers(ctx, "group", WithPageSize(2)) {
+		require.NoError(t, err)
+		ids = append(ids, user.GetId())
+		if len(ids) == 3 {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"user0", "user1", "user2"}, ids)
+	// only the pages needed to produce 3 users should be requested
+	assert.EqualValues(t, 2, requests.Load())
+}
+
+func TestAllContextCanceled(t *testing.T) {
+	requests := &atomic.Int32{}
+	client, _ := newTestClient(t, pagedUsersHandler(10, 2, requests))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var iterErr error
+	count := 0
+	for _, err := range client.GroupUsers(ctx, "group", WithPageSize(2)) {
+		if err != nil {
+			iterErr = err
+			break
+		}
+		count++
+		cancel()
+	}
+
+	assert.ErrorIs(t, iterErr, context.Canceled)
+	assert.Equal(t, 2, count)
+}
+
+func TestPagesUseRequestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := &atomic.Int32{}
+	paged := pagedUsersHandler(10, 2, requests)
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after") != "" {
+			// hang until the request is canceled
+			cancel()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		paged(w, r)
+	}), WithMaxRetries(0))
+
+	start := time.Now()
+	var iterErr error
+	for _, err := range client.GroupUsers(ctx, "group", WithPageSize(2)) {
+		if err != nil {
+			iterErr = err
+		}
+	}
+
+	assert.ErrorIs(t, iterErr, context.Canceled)
+	assert.Less(t, time.Since(start), 2*time.Second, "the request for the second page is canceled with ctx")
+}
+
+func TestPagesNextLinkWithoutCursor(t *testing.T) {
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", fmt.Sprintf(`<http://%s%s?limit=2>; rel="next"`, r.Host, r.URL.Path))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"user0"}]`))
+	}))
+
+	_, err := client.ListGroupUsers(context.Background(), "group")
+	assert.ErrorContains(t, err, "has no after cursor")
+}
+
+func TestPagesRateLimited(t *testing.T) {
+	requests := &atomic.Int32{}
+	paged := pagedUsersHandler(10, 2, requests)
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("after") != "" {
+			w.Header().Set("X-Rate-Limit-Reset", "1700000000")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		paged(w, r)
+	}), WithMaxRetries(0))
+
+	_, err := client.ListGroupUsers(context.Background(), "group", WithLimit(2))
+
+	var rateLimitErr *RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr, "later pages are requested with the rate limit recorder")
+	assert.Equal(t, time.Unix(1700000000, 0), rateLimitErr.Reset)
+}
+
+func TestListGroupUsers(t *testing.T) {
+	requests := &atomic.Int32{}
+	client, _ := newTestClient(t, pagedUsersHandler(5, 2, requests))
+
+	users, err := client.ListGroupUsers(context.Background(), "group", WithLimit(2))
+	require.NoError(t, err)
+	assert.Len(t, users, 5)
+	assert.Equal(t, "user4", users[4].GetId())
+}
+
+func TestListGroupUsersError(t *testing.T) {
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}), WithMaxRetries(0))
+
+	users, err := client.ListGroupUsers(context.Background(), "group")
+	assert.Error(t, err)
+	assert.Nil(t, users)
+}
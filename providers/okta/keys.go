@@ -0,0 +1,201 @@
+package okta
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	josev3 "github.com/go-jose/go-jose/v3"
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// signerFromPEM builds a client assertion signer from a PEM encoded PKCS1, PKCS8 or SEC1 private key.
+// key may also be a path to a PEM file, matching the behavior of okta.WithPrivateKey.
+func signerFromPEM(key string, kid string) (josev3.Signer, error) {
+	signer, err := signerFromPEMBytes([]byte(key), kid)
+	if !errors.Is(err, errNotPEM) {
+		return signer, err
+	}
+
+	contents, err := os.ReadFile(key)
+	if err != nil {
+		return nil, fmt.Errorf("private key must be PEM encoded or a path to a PEM file")
+	}
+
+	signer, err = signerFromPEMBytes(contents, kid)
+	if errors.Is(err, errNotPEM) {
+		return nil, fmt.Errorf("private key file %q is not PEM encoded", key)
+	}
+	return signer, err
+}
+
+var errNotPEM = errors.New("private key is not PEM encoded")
+
+// signerFromPEMBytes builds a client assertion signer from PEM encoded private key data, which unlike
+// signerFromPEM is never resolved as a path.
+func signerFromPEMBytes(data []byte, kid string) (josev3.Signer, error) {
+	// keys passed through environment variables often have escaped newlines
+	data = bytes.ReplaceAll(data, []byte(`\n`), []byte("\n"))
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errNotPEM
+	}
+
+	privateKey, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, err
+	}
+
+	alg, err := algorithmForKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSigner(privateKey, alg, kid)
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.PrivateKey, error) {
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pkcs1 private key: %w", err)
+		}
+		return key, nil
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ec private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pkcs8 private key: %w", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key PEM type %q", block.Type)
+	}
+}
+
+// signerFromJWK builds a client assertion signer from a private JWK, using the JWK's alg when set
+// and otherwise the default algorithm for its key type. The JWK's kid is included in the assertion header.
+func signerFromJWK(jwk *jose.JSONWebKey) (josev3.Signer, error) {
+	if jwk.IsPublic() {
+		return nil, fmt.Errorf("jwk %q must contain a private key", jwk.KeyID)
+	}
+
+	alg, err := algorithmForKey(jwk.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	if jwk.Algorithm != "" {
+		if err := validateAlgorithm(jwk.Key, jose.SignatureAlgorithm(jwk.Algorithm)); err != nil {
+			return nil, err
+		}
+		alg = jose.SignatureAlgorithm(jwk.Algorithm)
+	}
+
+	return newSigner(jwk.Key, alg, jwk.KeyID)
+}
+
+// newSigner creates the signer used by the SDK for client assertions.
+// The SDK is built on go-jose v3 so the signer must be as well.
+func newSigner(key crypto.PrivateKey, alg jose.SignatureAlgorithm, kid string) (josev3.Signer, error) {
+	var signerOptions *josev3.SignerOptions
+	if kid != "" {
+		signerOptions = (&josev3.SignerOptions{}).WithHeader("kid", kid)
+	}
+
+	signer, err := josev3.NewSigner(josev3.SigningKey{Algorithm: josev3.SignatureAlgorithm(alg), Key: key}, signerOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s signer: %w", alg, err)
+	}
+	return signer, nil
+}
+
+// algorithmForKey returns the signing algorithm Okta expects for the private key's type and curve.
+func algorithmForKey(key crypto.PrivateKey) (jose.SignatureAlgorithm, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jose.RS256, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", fmt.Errorf("unsupported ec curve %s", k.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("unsupported private key type %T, must be RSA or EC", key)
+	}
+}
+
+// validateAlgorithm checks that alg can be used with the private key.
+func validateAlgorithm(key crypto.PrivateKey, alg jose.SignatureAlgorithm) error {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		switch alg {
+		case jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512:
+			return nil
+		}
+	case *ecdsa.PrivateKey:
+		// each EC curve only supports a single algorithm
+		if expected, err := algorithmForKey(key); err == nil && expected == alg {
+			return nil
+		}
+	}
+	return fmt.Errorf("algorithm %q is not supported for private key type %T", alg, key)
+}
+
+// jwkFromBytes parses a single JWK or a JWK Set. When kid is set the key with that id is returned,
+// otherwise a JWK Set must contain exactly one key.
+func jwkFromBytes(bytes []byte, kid string) (*jose.JSONWebKey, error) {
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(bytes, &set); err == nil && len(set.Keys) > 0 {
+		return jwkFromSet(&set, kid)
+	}
+
+	jwk := &jose.JSONWebKey{}
+	if err := json.Unmarshal(bytes, jwk); err != nil {
+		return nil, fmt.Errorf("failed to marhsal jwk bytes to json: %w", err)
+	}
+
+	if kid != "" {
+		if jwk.KeyID != "" && jwk.KeyID != kid {
+			return nil, fmt.Errorf("jwk kid %q does not match requested kid %q", jwk.KeyID, kid)
+		}
+		jwk.KeyID = kid
+	}
+
+	return jwk, nil
+}
+
+func jwkFromSet(set *jose.JSONWebKeySet, kid string) (*jose.JSONWebKey, error) {
+	if kid == "" {
+		if len(set.Keys) != 1 {
+			return nil, fmt.Errorf("jwk set contains %d keys, a kid must be provided to select one", len(set.Keys))
+		}
+		return &set.Keys[0], nil
+	}
+
+	keys := set.Key(kid)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwk set does not contain a key with kid %q", kid)
+	}
+	return &keys[0], nil
+}
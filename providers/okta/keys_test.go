@@ -0,0 +1,283 @@
+package okta
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.ES256, jose.ES384, jose.ES512}
+
+func testECKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	block, _ := pem.Decode([]byte(testPrivateKey(t)))
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	return key
+}
+
+func pkcs8PEM(t *testing.T, key crypto.PrivateKey) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func jwkJSON(t *testing.T, key crypto.PrivateKey, kid string, alg string) []byte {
+	data, err := json.Marshal(jose.JSONWebKey{Key: key, KeyID: kid, Algorithm: alg})
+	require.NoError(t, err)
+	return data
+}
+
+// assertionHeader signs a payload with the signer built for the client and returns its protected header.
+func assertionHeader(t *testing.T, client *Client) jose.Header {
+	t.Helper()
+
+	jws, err := client.GetConfig().PrivateKeySigner.Sign([]byte("payload"))
+	require.NoError(t, err)
+	compact, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	parsed, err := jose.ParseSigned(compact, testAlgorithms)
+	require.NoError(t, err)
+	return parsed.Signatures[0].Header
+}
+
+func TestNewClientKeyTypes(t *testing.T) {
+	rsaKey := testRSAKey(t)
+	p256 := testECKey(t, elliptic.P256())
+	p384 := testECKey(t, elliptic.P384())
+
+	sec1, err := x509.MarshalECPrivateKey(p256)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		key     string
+		kid     string
+		wantAlg string
+	}{
+		"pkcs1 rsa": {
+			key:     testPrivateKey(t),
+			wantAlg: "RS256",
+		},
+		"pkcs1 rsa with escaped newlines": {
+			key:     strings.ReplaceAll(testPrivateKey(t), "\n", `\n`),
+			wantAlg: "RS256",
+		},
+		"pkcs8 rsa": {
+			key:     pkcs8PEM(t, rsaKey),
+			kid:     "rsa-kid",
+			wantAlg: "RS256",
+		},
+		"pkcs8 p256": {
+			key:     pkcs8PEM(t, p256),
+			wantAlg: "ES256",
+		},
+		"pkcs8 p384": {
+			key:     pkcs8PEM(t, p384),
+			kid:     "ec-kid",
+			wantAlg: "ES384",
+		},
+		"sec1 p256": {
+			key:     string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1})),
+			wantAlg: "ES256",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewClientWithOptions("https://example.okta.com", "client", test.key, WithKeyID(test.kid))
+			require.NoError(t, err)
+
+			header := assertionHeader(t, client)
+			assert.Equal(t, test.wantAlg, header.Algorithm)
+			assert.Equal(t, test.kid, header.KeyID)
+		})
+	}
+}
+
+func TestNewClientKeyFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	require.NoError(t, os.WriteFile(path, []byte(pkcs8PEM(t, testECKey(t, elliptic.P384()))), 0o600))
+
+	client, err := NewClient("https://example.okta.com", "client", path)
+	require.NoError(t, err)
+	assert.Equal(t, "ES384", assertionHeader(t, client).Algorithm)
+}
+
+func TestNewClientInvalidKeys(t *testing.T) {
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"not pem":          "not a key",
+		"unknown pem type": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{0}})),
+		"ed25519":          pkcs8PEM(t, ed25519Key),
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewClient("https://example.okta.com", "client", key)
+			assert.Error(t, err)
+			assert.Nil(t, client)
+		})
+	}
+}
+
+func TestNewClientFromJWKBytes(t *testing.T) {
+	rsaKey := testRSAKey(t)
+	p256 := testECKey(t, elliptic.P256())
+	p384 := testECKey(t, elliptic.P384())
+
+	set := func(keys ...[]byte) []byte {
+		raw := []json.RawMessage{}
+		for _, key := range keys {
+			raw = append(raw, key)
+		}
+		data, err := json.Marshal(map[string]any{"keys": raw})
+		require.NoError(t, err)
+		return data
+	}
+
+	tests := map[string]struct {
+		jwk     []byte
+		kid     string
+		wantAlg string
+		wantKid string
+		wantErr bool
+	}{
+		"rsa jwk": {
+			jwk:     jwkJSON(t, rsaKey, "rsa", ""),
+			wantAlg: "RS256",
+			wantKid: "rsa",
+		},
+		"rsa jwk with alg": {
+			jwk:     jwkJSON(t, rsaKey, "rsa", "RS384"),
+			wantAlg: "RS384",
+			wantKid: "rsa",
+		},
+		"ec p256 jwk": {
+			jwk:     jwkJSON(t, p256, "ec256", ""),
+			wantAlg: "ES256",
+			wantKid: "ec256",
+		},
+		"ec p384 jwk": {
+			jwk:     jwkJSON(t, p384, "ec384", "ES384"),
+			wantAlg: "ES384",
+			wantKid: "ec384",
+		},
+		"kid option for jwk without kid": {
+			jwk:     jwkJSON(t, p256, "", ""),
+			kid:     "configured",
+			wantAlg: "ES256",
+			wantKid: "configured",
+		},
+		"kid mismatch": {
+			jwk:     jwkJSON(t, p256, "ec256", ""),
+			kid:     "other",
+			wantErr: true,
+		},
+		"mismatched alg": {
+			jwk:     jwkJSON(t, p384, "ec384", "ES256"),
+			wantErr: true,
+		},
+		"public key": {
+			jwk:     jwkJSON(t, &p256.PublicKey, "ec256", ""),
+			wantErr: true,
+		},
+		"set with single key": {
+			jwk:     set(jwkJSON(t, p384, "ec384", "")),
+			wantAlg: "ES384",
+			wantKid: "ec384",
+		},
+		"set selected by kid": {
+			jwk:     set(jwkJSON(t, rsaKey, "rsa", ""), jwkJSON(t, p384, "ec384", "")),
+			kid:     "ec384",
+			wantAlg: "ES384",
+			wantKid: "ec384",
+		},
+		"set without kid": {
+			jwk:     set(jwkJSON(t, rsaKey, "rsa", ""), jwkJSON(t, p384, "ec384", "")),
+			wantErr: true,
+		},
+		"set missing kid": {
+			jwk:     set(jwkJSON(t, rsaKey, "rsa", "")),
+			kid:     "missing",
+			wantErr: true,
+		},
+		"invalid json": {
+			jwk:     []byte("{"),
+			wantErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, err := NewClientFromJWKBytesWithOptions("https://example.okta.com", "client", test.jwk, WithKeyID(test.kid))
+			if test.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, client)
+				return
+			}
+			require.NoError(t, err)
+
+			header := assertionHeader(t, client)
+			assert.Equal(t, test.wantAlg, header.Algorithm)
+			assert.Equal(t, test.wantKid, header.KeyID)
+		})
+	}
+}
+
+func TestClientAssertionUsesECKey(t *testing.T) {
+	key := testECKey(t, elliptic.P384())
+
+	var assertion string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		assertion = r.FormValue("client_assertion")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token_type":"Bearer","access_token":"test-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/api/v1/groups/group/users", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClientFromJWKBytes(server.URL, "client", jwkJSON(t, key, "ec-kid", ""))
+	require.NoError(t, err)
+
+	_, err = client.ListGroupUsers(context.Background(), "group")
+	require.NoError(t, err)
+
+	parsed, err := jose.ParseSigned(assertion, testAlgorithms)
+	require.NoError(t, err)
+	assert.Equal(t, "ES384", parsed.Signatures[0].Header.Algorithm)
+	assert.Equal(t, "ec-kid", parsed.Signatures[0].Header.KeyID)
+
+	_, err = parsed.Verify(&key.PublicKey)
+	assert.NoError(t, err)
+}
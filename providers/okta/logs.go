@@ -0,0 +1,292 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// LogCheckpoint is the position of a LogTailer in the System Log.
+type LogCheckpoint struct {
+	// After is the cursor from the System Log next link that polling resumes from
+	After string `json:"after,omitempty"`
+	// Published is the time of the last delivered event, polling resumes from it when After is not set
+	Published time.Time `json:"published,omitempty"`
+	// UUIDs are the delivered events published at Published. Okta includes events published at the since
+	// time, so these are skipped when polling resumes from Published rather than delivered again.
+	UUIDs []string `json:"uuids,omitempty"`
+}
+
+// CheckpointStore persists the LogTailer checkpoint so polling resumes where it stopped after a restart.
+type CheckpointStore interface {
+	// Load returns the saved checkpoint or nil when there is none.
+	Load(ctx context.Context) (*LogCheckpoint, error)
+	Save(ctx context.Context, checkpoint LogCheckpoint) error
+}
+
+// MemoryCheckpointStore keeps the checkpoint in memory, the zero value is ready to use.
+type MemoryCheckpointStore struct {
+	mu         sync.Mutex
+	checkpoint *LogCheckpoint
+}
+
+func (s *MemoryCheckpointStore) Load(_ context.Context) (*LogCheckpoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.checkpoint == nil {
+		return nil, nil
+	}
+	checkpoint := *s.checkpoint
+	return &checkpoint, nil
+}
+
+func (s *MemoryCheckpointStore) Save(_ context.Context, checkpoint LogCheckpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checkpoint.UUIDs = slices.Clone(checkpoint.UUIDs)
+	s.checkpoint = &checkpoint
+	return nil
+}
+
+// FileCheckpointStore keeps the checkpoint as JSON in a file, which is replaced atomically on save.
+type FileCheckpointStore struct {
+	path string
+}
+
+func NewFileCheckpointStore(path string) *FileCheckpointStore {
+	return &FileCheckpointStore{path: path}
+}
+
+func (s *FileCheckpointStore) Load(_ context.Context) (*LogCheckpoint, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint file: %w", err)
+	}
+
+	checkpoint := &LogCheckpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint file %q: %w", s.path, err)
+	}
+	return checkpoint, nil
+}
+
+func (s *FileCheckpointStore) Save(_ context.Context, checkpoint LogCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint file: %w", err)
+	}
+	return nil
+}
+
+// LogTailer polls the Okta System Log for new events.
+/*
+
+Example usage:
+
+tailer := client.NewLogTailer(
+	okta.WithCheckpointStore(okta.NewFileCheckpointStore("/var/lib/audit/okta.json")),
+	okta.WithLogFilter(okta.Eq("eventType", "group.user_membership.add")),
+)
+
+events := make(chan oktasdk.LogEvent, 100)
+go func() {
+	for event := range events {
+		log.Info(event.GetDisplayMessage())
+	}
+}()
+
+if err := tailer.Run(ctx, events); err != nil {
+	log.Fatal(err)
+}
+*/
+type LogTailer struct {
+	client   *Client
+	store    CheckpointStore
+	interval time.Duration
+	pageSize int32
+	filter   Expr
+	since    time.Time
+}
+
+type LogTailerOpt func(*LogTailer)
+
+// WithCheckpointStore sets where the tailer's position is persisted, default: in memory.
+func WithCheckpointStore(store CheckpointStore) LogTailerOpt {
+	return func(t *LogTailer) {
+		t.store = store
+	}
+}
+
+// WithPollInterval sets how long the tailer waits before polling again once it has caught up, default: 15s.
+func WithPollInterval(interval time.Duration) LogTailerOpt {
+	return func(t *LogTailer) {
+		t.interval = interval
+	}
+}
+
+// WithLogPageSize sets the number of events requested per poll, default: 1000 which is the API maximum.
+func WithLogPageSize(size int32) LogTailerOpt {
+	return func(t *LogTailer) {
+		t.pageSize = size
+	}
+}
+
+// WithLogFilter only returns events matching the filter expression.
+func WithLogFilter(filter Expr) LogTailerOpt {
+	return func(t *LogTailer) {
+		t.filter = filter
+	}
+}
+
+// WithSince sets where polling starts when the store has no checkpoint, default: when Run is called.
+func WithSince(since time.Time) LogTailerOpt {
+	return func(t *LogTailer) {
+		t.since = since
+	}
+}
+
+// NewLogTailer returns a LogTailer that reads the System Log with the client.
+func (c *Client) NewLogTailer(opts ...LogTailerOpt) *LogTailer {
+	t := &LogTailer{
+		client:   c,
+		store:    &MemoryCheckpointStore{},
+		interval: 15 * time.Second,
+		pageSize: 1000,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Run polls the System Log in published order starting from the stored checkpoint, following the
+// next link of each response, and sends each event to events. Sends block until the event is received,
+// so a slow consumer pauses polling rather than buffering events in memory.
+// The checkpoint is saved after every event of a page has been received; when Run is stopped part way
+// through a page the events of that page are delivered again by the next Run.
+// Run closes events when it returns, it returns nil when ctx is cancelled.
+func (t *LogTailer) Run(ctx context.Context, events chan<- okta.LogEvent) error {
+	defer close(events)
+
+	checkpoint := LogCheckpoint{}
+	saved, err := t.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load okta system log checkpoint: %w", err)
+	}
+	if saved != nil {
+		checkpoint = *saved
+	}
+
+	since := t.since
+	if since.IsZero() {
+		since = time.Now()
+	}
+
+	ctx = withRateLimitRecorder(ctx)
+	for {
+		query := t.client.SystemLogAPI.ListLogEvents(ctx).SortOrder("ASCENDING").Limit(t.pageSize)
+		if t.filter != nil {
+			query = query.Filter(t.filter.String())
+		}
+		switch {
+		case checkpoint.After != "":
+			query = query.After(checkpoint.After)
+		case !checkpoint.Published.IsZero():
+			query = query.Since(checkpoint.Published)
+		default:
+			query = query.Since(since)
+		}
+
+		page, resp, err := query.Execute()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := apiError(ctx, resp, err); err != nil {
+			return fmt.Errorf("failed to poll okta system log: %w", err)
+		}
+
+		for _, event := range page {
+			published := event.GetPublished()
+			if published.Equal(checkpoint.Published) && slices.Contains(checkpoint.UUIDs, event.GetUuid()) {
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return nil
+			}
+			if event.Published == nil {
+				continue
+			}
+			if !published.Equal(checkpoint.Published) {
+				checkpoint.Published = published
+				checkpoint.UUIDs = nil
+			}
+			checkpoint.UUIDs = append(checkpoint.UUIDs, event.GetUuid())
+		}
+
+		after := nextCursor(resp)
+		if len(page) > 0 || (after != "" && after != checkpoint.After) {
+			if after != "" {
+				checkpoint.After = after
+			}
+			// the page has been delivered, so save the checkpoint even if Run is being stopped
+			if err := t.store.Save(context.WithoutCancel(ctx), checkpoint); err != nil {
+				return fmt.Errorf("failed to save okta system log checkpoint: %w", err)
+			}
+		}
+
+		// a partial page means the tailer has caught up
+		if len(page) < int(t.pageSize) {
+			select {
+			case <-time.After(t.interval):
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}
+}
+
+// nextCursor returns the after cursor from the response's next link.
+func nextCursor(resp *okta.APIResponse) string {
+	if resp == nil || !resp.HasNextPage() {
+		return ""
+	}
+	next, err := url.Parse(resp.NextPage())
+	if err != nil {
+		return ""
+	}
+	return next.Query().Get("after")
+}
@@ -0,0 +1,181 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogServer(t *testing.T, count int) (*Client, *oktatest.Server, time.Time) {
+	client, server := newSeededClient(t, oktatest.Fixtures{})
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := range count {
+		server.AddLogEvents(oktatest.LogEvent{
+			UUID:      fmt.Sprintf("e%d", i),
+			Published: start.Add(time.Duration(i) * time.Second),
+			EventType: []string{"user.session.start", "group.user_membership.add"}[i%2],
+		})
+	}
+
+	return client, server, start
+}
+
+// runTailer starts the tailer and returns the events channel and a channel receiving Run's result.
+func runTailer(ctx context.Context, tailer *LogTailer, buffer int) (chan okta.LogEvent, chan error) {
+	events := make(chan okta.LogEvent, buffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- tailer.Run(ctx, events)
+	}()
+	return events, done
+}
+
+func receiveEvents(t *testing.T, events <-chan okta.LogEvent, count int) []string {
+	ids := []string{}
+	for range count {
+		select {
+		case event := <-events:
+			ids = append(ids, event.GetUuid())
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timed out waiting for log events", "received %v", ids)
+		}
+	}
+	return ids
+}
+
+func TestLogTailer(t *testing.T) {
+	client, server, start := newLogServer(t, 5)
+	store := &MemoryCheckpointStore{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer := client.NewLogTailer(WithSince(start), WithLogPageSize(2), WithPollInterval(10*time.Millisecond), WithCheckpointStore(store))
+	events, done := runTailer(ctx, tailer, 0)
+
+	assert.Equal(t, []string{"e0", "e1", "e2", "e3", "e4"}, receiveEvents(t, events, 5))
+
+	server.AddLogEvents(oktatest.LogEvent{UUID: "e5"}, oktatest.LogEvent{UUID: "e6"})
+	assert.Equal(t, []string{"e5", "e6"}, receiveEvents(t, events, 2))
+
+	require.Eventually(t, func() bool {
+		checkpoint, err := store.Load(ctx)
+		return err == nil && checkpoint != nil && checkpoint.After == "e6"
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	_, open := <-events
+	assert.False(t, open, "events should be closed when Run returns")
+}
+
+func TestLogTailerResumesFromCheckpoint(t *testing.T) {
+	client, _, _ := newLogServer(t, 5)
+	store := NewFileCheckpointStore(filepath.Join(t.TempDir(), "checkpoint.json"))
+
+	checkpoint, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+
+	require.NoError(t, store.Save(context.Background(), LogCheckpoint{After: "e2"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer := client.NewLogTailer(WithCheckpointStore(store), WithPollInterval(10*time.Millisecond))
+	events, done := runTailer(ctx, tailer, 10)
+	assert.Equal(t, []string{"e3", "e4"}, receiveEvents(t, events, 2))
+
+	require.Eventually(t, func() bool {
+		checkpoint, err := store.Load(ctx)
+		return err == nil && checkpoint.After == "e4"
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	checkpoint, err = store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "e4", checkpoint.After)
+	assert.False(t, checkpoint.Published.IsZero())
+}
+
+func TestLogTailerResumesFromPublished(t *testing.T) {
+	client, server, start := newLogServer(t, 3)
+	// e3 is published at the same time as e2
+	server.AddLogEvents(oktatest.LogEvent{UUID: "e3", Published: start.Add(2 * time.Second)})
+	store := &MemoryCheckpointStore{}
+	require.NoError(t, store.Save(context.Background(), LogCheckpoint{Published: start.Add(2 * time.Second), UUIDs: []string{"e2"}}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer := client.NewLogTailer(WithCheckpointStore(store), WithPollInterval(10*time.Millisecond))
+	events, done := runTailer(ctx, tailer, 10)
+	assert.Equal(t, []string{"e3"}, receiveEvents(t, events, 1), "events delivered before the checkpoint are skipped")
+
+	require.Eventually(t, func() bool {
+		checkpoint, err := store.Load(ctx)
+		return err == nil && checkpoint.After == "e3"
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Empty(t, events)
+
+	checkpoint, err := store.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e2", "e3"}, checkpoint.UUIDs)
+}
+
+func TestLogTailerBackpressure(t *testing.T) {
+	client, server, start := newLogServer(t, 6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer := client.NewLogTailer(WithSince(start), WithLogPageSize(2), WithPollInterval(time.Millisecond))
+	events, done := runTailer(ctx, tailer, 0)
+
+	assert.Equal(t, []string{"e0"}, receiveEvents(t, events, 1))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, countRequests(server, "GET /api/v1/logs"), "no more pages should be requested until events are received")
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestLogTailerFilter(t *testing.T) {
+	client, server, start := newLogServer(t, 6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tailer := client.NewLogTailer(WithSince(start), WithLogFilter(Eq("eventType", "group.user_membership.add")), WithPollInterval(time.Millisecond))
+	events, done := runTailer(ctx, tailer, 10)
+	assert.Equal(t, []string{"e1", "e3", "e5"}, receiveEvents(t, events, 3))
+
+	server.AddLogEvents(oktatest.LogEvent{UUID: "e6", EventType: "user.session.start"}, oktatest.LogEvent{UUID: "e7", EventType: "group.user_membership.add"})
+	assert.Equal(t, []string{"e7"}, receiveEvents(t, events, 1))
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestLogTailerError(t *testing.T) {
+	client, server, _ := newLogServer(t, 1)
+	server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/logs", Status: http.StatusForbidden})
+
+	events := make(chan okta.LogEvent)
+	err := client.NewLogTailer().Run(context.Background(), events)
+	assert.ErrorContains(t, err, "failed to poll okta system log")
+}
@@ -1,52 +1,202 @@
 package okta
 
 import (
+	"cmp"
 	"context"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/json"
-	"encoding/pem"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
-	jose "github.com/go-jose/go-jose/v4"
+	josev3 "github.com/go-jose/go-jose/v3"
 	"github.com/okta/okta-sdk-golang/v5/okta"
 )
 
 type Client struct {
 	*okta.APIClient
+
+	// credentials is the signer of clients created by NewClientFromSource, its key can be reloaded
+	credentials *rotatingSigner
 }
 
+// NewClientFromJWKBytes creates a Client that authenticates with the private key in a JWK or JWK Set
+// and requests an access token with the scopes. Use NewClientFromJWKBytesWithOptions to configure the client further.
 func NewClientFromJWKBytes(orgURL string, clientID string, jwkBytes []byte, scopes ...string) (*Client, error) {
-	jwk, err := jwkFromBytes(jwkBytes)
+	return NewClientFromJWKBytesWithOptions(orgURL, clientID, jwkBytes, WithScopes(scopes...))
+}
+
+// NewClientFromJWKBytesWithOptions creates a Client that authenticates with the private key in a JWK or JWK Set.
+// RSA and EC (P-256, P-384 and P-521) keys are supported. The JWK's kid is sent with the client assertion
+// and selects the key from a JWK Set when provided with WithKeyID.
+func NewClientFromJWKBytesWithOptions(orgURL string, clientID string, jwkBytes []byte, opts ...ClientOpt) (*Client, error) {
+	options := newClientOptions(opts...)
+
+	jwk, err := jwkFromBytes(jwkBytes, options.keyID)
 	if err != nil {
 		return nil, err
 	}
-	pem, err := jwkToRSA(jwk)
+
+	signer, err := signerFromJWK(jwk)
 	if err != nil {
 		return nil, err
 	}
-	return NewClient(orgURL, clientID, pem, scopes...)
+
+	return newClient(orgURL, clientID, signer, options)
 }
 
+// NewClient creates a Client that authenticates with a PEM encoded private key, or a path to one,
+// and requests an access token with the scopes. Use NewClientWithOptions to configure the client further.
 func NewClient(orgURL string, clientID string, key string, scopes ...string) (*Client, error) {
+	return NewClientWithOptions(orgURL, clientID, key, WithScopes(scopes...))
+}
+
+// NewClientWithOptions creates a Client that authenticates with a PEM encoded RSA or EC private key in PKCS1, PKCS8
+// or SEC1 format, or a path to one. The signing algorithm is chosen based on the key type.
+/*
+
+Example usage:
+
+client, err := okta.NewClientWithOptions(orgURL, clientID, key,
+	okta.WithScopes("okta.groups.read", "okta.users.read"),
+	okta.WithMaxRetries(5),
+)
+*/
+func NewClientWithOptions(orgURL string, clientID string, key string, opts ...ClientOpt) (*Client, error) {
+	options := newClientOptions(opts...)
+
+	signer, err := signerFromPEM(key, options.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newClient(orgURL, clientID, signer, options)
+}
+
+func newClient(orgURL string, clientID string, signer josev3.Signer, options *clientOptions) (*Client, error) {
+	httpClient := &http.Client{
+		Transport: newRateLimitTransport(options),
+	}
+
 	config, err := okta.NewConfiguration(
 		okta.WithOrgUrl(orgURL),
 		okta.WithClientId(clientID),
-		okta.WithScopes(scopes),
+		okta.WithScopes(options.scopes),
 		okta.WithAuthorizationMode("PrivateKey"),
-		okta.WithPrivateKey(key),
+		okta.WithPrivateKeySigner(signer),
+		okta.WithHttpClientPtr(httpClient),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build okta configuration: %w", err)
 	}
 
+	// the SDK drops the port from the org URL, keep it so non-standard ports can be targeted
+	if u, err := url.Parse(orgURL); err == nil && u.Port() != "" {
+		config.Host = u.Host
+	}
+
 	client := okta.NewAPIClient(config)
-	return &Client{client}, nil
+	return &Client{APIClient: client}, nil
+}
+
+// clientOptions holds the configuration applied by ClientOpt functions when building a Client.
+type clientOptions struct {
+	// scopes are the OAuth scopes requested for the access token
+	scopes []string
+	// baseTransport performs the HTTP requests, default: http.DefaultTransport
+	baseTransport http.RoundTripper
+	// maxRetries is the number of times a rate limited or failed request is retried, default: 3
+	maxRetries int
+	// minBackoff is the initial delay between retries, default: 500ms
+	minBackoff time.Duration
+	// maxBackoff caps the exponential delay between retries, default: 30s
+	maxBackoff time.Duration
+	// rateLimitThreshold is the number of remaining requests in a rate limit bucket
+	// at which requests are paused until the bucket resets, default: 1
+	rateLimitThreshold int
+	// keyID is the kid sent with client assertions and used to select a key from a JWK Set
+	keyID string
+	// auditHooks are called before and after every request sent to Okta
+	auditHooks []AuditHook
+	// auditRedactedFields are redacted from request bodies passed to audit hooks, in addition to the defaults
+	auditRedactedFields []string
+}
+
+func defaultClientOptions() *clientOptions {
+	return &clientOptions{
+		baseTransport:      http.DefaultTransport,
+		maxRetries:         3,
+		minBackoff:         500 * time.Millisecond,
+		maxBackoff:         30 * time.Second,
+		rateLimitThreshold: 1,
+	}
+}
+
+func newClientOptions(opts ...ClientOpt) *clientOptions {
+	options := defaultClientOptions()
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+type ClientOpt func(*clientOptions)
+
+// WithScopes sets the OAuth scopes requested for the client's access token, e.g. "okta.groups.read".
+func WithScopes(scopes ...string) ClientOpt {
+	return func(o *clientOptions) {
+		o.scopes = scopes
+	}
+}
+
+// WithKeyID sets the kid of the private key registered with the Okta application.
+// For NewClientFromJWKBytesWithOptions it also selects the key from a JWK Set.
+func WithKeyID(kid string) ClientOpt {
+	return func(o *clientOptions) {
+		o.keyID = kid
+	}
+}
+
+// WithBaseTransport sets the HTTP transport used to reach the Okta API.
+// The default is http.DefaultTransport.
+func WithBaseTransport(transport http.RoundTripper) ClientOpt {
+	return func(o *clientOptions) {
+		o.baseTransport = transport
+	}
+}
+
+// WithMaxRetries sets how many times a request that is rate limited or fails with a
+// server error is retried. Zero disables retries.
+func WithMaxRetries(maxRetries int) ClientOpt {
+	return func(o *clientOptions) {
+		o.maxRetries = maxRetries
+	}
+}
+
+// WithBackoff sets the initial and maximum delay of the jittered exponential backoff between retries.
+func WithBackoff(minBackoff, maxBackoff time.Duration) ClientOpt {
+	return func(o *clientOptions) {
+		o.minBackoff = minBackoff
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithRateLimitThreshold sets the number of remaining requests reported by X-Rate-Limit-Remaining
+// at which the client stops sending requests to that endpoint until X-Rate-Limit-Reset.
+// A negative value disables proactive pausing.
+func WithRateLimitThreshold(remaining int) ClientOpt {
+	return func(o *clientOptions) {
+		o.rateLimitThreshold = remaining
+	}
 }
 
+// ListGroupUsersOpt modifies the request ListGroupUsers makes. Client.GroupUsers accepts ListOpt instead.
 type ListGroupUsersOpt func(okta.ApiListGroupUsersRequest) okta.ApiListGroupUsersRequest
 
+// WithLimit sets the number of group members requested per page by ListGroupUsers.
 func WithLimit(limit int32) ListGroupUsersOpt {
 	return func(r okta.ApiListGroupUsersRequest) okta.ApiListGroupUsersRequest {
 		return r.Limit(limit)
@@ -54,23 +204,22 @@ func WithLimit(limit int32) ListGroupUsersOpt {
 }
 
 func (c *Client) ListGroupUsers(ctx context.Context, groupId string, opts ...ListGroupUsersOpt) ([]okta.GroupMember, error) {
+	ctx = withRateLimitRecorder(ctx)
 	query := c.GroupAPI.ListGroupUsers(ctx, groupId)
 	for _, opt := range opts {
 		query = opt(query)
 	}
 
-	users, resp, err := query.Execute()
-	if err != nil {
-		return nil, fmt.Errorf("failed to query okta group users: %w", err)
-	}
-
-	for resp.HasNextPage() {
-		var nextSet []okta.GroupMember
-		resp, err = resp.Next(&nextSet)
+	users := []okta.GroupMember{}
+	for user, err := range All(ctx, query) {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w %q: %w", ErrGroupNotFound, groupId, err)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to receive pagination results: %w", err)
+			return nil, fmt.Errorf("failed to query okta group users: %w", err)
 		}
-		users = append(users, nextSet...)
+		users = append(users, user)
 	}
 
 	return users, nil
@@ -78,9 +227,10 @@ func (c *Client) ListGroupUsers(ctx context.Context, groupId string, opts ...Lis
 
 func (c *Client) GroupByName(ctx context.Context, groupName string) (okta.Group, error) {
 
+	ctx = withRateLimitRecorder(ctx)
 	query := c.GroupAPI.ListGroups(ctx).Q(groupName)
-	oktaGroups, _, err := query.Execute()
-	if err != nil {
+	oktaGroups, resp, err := query.Execute()
+	if err := apiError(ctx, resp, err); err != nil {
 		return okta.Group{}, fmt.Errorf("failed to query okta group: %w", err)
 	}
 
@@ -90,35 +240,192 @@ func (c *Client) GroupByName(ctx context.Context, groupName string) (okta.Group,
 		}
 	}
 
-	return okta.Group{}, fmt.Errorf("unable to find okta group %q", groupName)
+	return okta.Group{}, fmt.Errorf("%w %q", ErrGroupNotFound, groupName)
 }
 
-func (c *Client) GroupsByName(ctx context.Context, groupNames []string, batchsize int) ([]okta.Group, error) {
-	groups := []okta.Group{}
+type groupsByNameOptions struct {
+	concurrency int
+	listOpts    []ListOpt
+}
+
+type GroupsByNameOpt func(*groupsByNameOptions)
+
+// WithConcurrency sets the number of batches GroupsByName looks up in parallel, default: 1.
+// All workers share the client's rate limit handling, so they pause together when a bucket runs low.
+func WithConcurrency(workers int) GroupsByNameOpt {
+	return func(o *groupsByNameOptions) {
+		o.concurrency = workers
+	}
+}
 
-	batches := buildFilterNameBatches(groupNames, batchsize)
+// WithListOpts sets request parameters on the searches GroupsByName makes, e.g. WithExpand to replace the
+// default "stats" expansion. The search parameter is always the batch's names.
+func WithListOpts(opts ...ListOpt) GroupsByNameOpt {
+	return func(o *groupsByNameOptions) {
+		o.listOpts = append(o.listOpts, opts...)
+	}
+}
 
-	for _, filter := range batches {
-		// expanding stats to get the number of users in the group
-		query := c.GroupAPI.ListGroups(ctx).Search(filter).Expand("stats")
-		oktaGroups, resp, err := query.Execute()
-		for {
-			if err != nil {
-				return nil, fmt.Errorf("failed to query okta group: %w", err)
+// GroupsNotFoundError is returned by GroupsByName, along with the groups that were found,
+// when some of the requested names do not match a group.
+type GroupsNotFoundError struct {
+	Names []string
+}
+
+func (e *GroupsNotFoundError) Error() string {
+	return fmt.Sprintf("unable to find okta groups %q", e.Names)
+}
+
+// Is matches ErrGroupNotFound.
+func (e *GroupsNotFoundError) Is(target error) bool {
+	return target == ErrGroupNotFound
+}
+
+// GroupsByName looks up groups by name in batches of batchsize names per search request.
+// Groups are returned in the order their names were requested and each group is returned once.
+// When some names are not found the groups that were found are returned with a *GroupsNotFoundError.
+func (c *Client) GroupsByName(ctx context.Context, groupNames []string, batchsize int, opts ...GroupsByNameOpt) ([]okta.Group, error) {
+	options := &groupsByNameOptions{concurrency: 1}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	// names are matched case insensitively, as they are by the search API
+	names := []string{}
+	positions := map[string]int{}
+	for _, name := range groupNames {
+		key := strings.ToLower(name)
+		if _, ok := positions[key]; !ok {
+			positions[key] = len(names)
+			names = append(names, name)
+		}
+	}
+
+	batches := buildFilterNameBatches(names, batchsize)
+	results, err := c.searchGroupBatches(ctx, batches, options.concurrency, options.listOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []okta.Group{}
+	seen := map[string]bool{}
+	found := make([]bool, len(names))
+	for _, batch := range results {
+		for _, group := range batch {
+			if seen[group.GetId()] {
+				continue
 			}
+			seen[group.GetId()] = true
+			groups = append(groups, group)
+
+			if i, ok := positions[strings.ToLower(groupName(group))]; ok {
+				found[i] = true
+			}
+		}
+	}
+
+	// groups that are not an exact match for a requested name are sorted last
+	position := func(group okta.Group) int {
+		if i, ok := positions[strings.ToLower(groupName(group))]; ok {
+			return i
+		}
+		return len(names)
+	}
+	slices.SortStableFunc(groups, func(a, b okta.Group) int {
+		if n := cmp.Compare(position(a), position(b)); n != 0 {
+			return n
+		}
+		return strings.Compare(a.GetId(), b.GetId())
+	})
+
+	missing := []string{}
+	for i, name := range names {
+		if !found[i] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return groups, &GroupsNotFoundError{Names: missing}
+	}
+
+	return groups, nil
+}
+
+// searchGroupBatches runs the search filters using up to concurrency workers and returns the
+// groups for each filter in the same order as filters. The first error stops the remaining searches.
+func (c *Client) searchGroupBatches(ctx context.Context, filters []string, concurrency int, opts []ListOpt) ([][]okta.Group, error) {
+	ctx, cancel := context.WithCancel(withRateLimitRecorder(ctx))
+	defer cancel()
 
-			groups = append(groups, oktaGroups...)
+	results := make([][]okta.Group, len(filters))
 
-			if !resp.HasNextPage() {
-				break
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+
+	jobs := make(chan int)
+	for range max(concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				groups, err := c.searchGroups(ctx, filters[i], opts)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				results[i] = groups
 			}
-			oktaGroups = []okta.Group{}
-			resp, err = resp.Next(&oktaGroups)
+		}()
+	}
+
+	for i := range filters {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query okta group: %w", err)
+	}
+
+	return results, nil
+}
+
+func (c *Client) searchGroups(ctx context.Context, filter string, opts []ListOpt) ([]okta.Group, error) {
+	groups := []okta.Group{}
+	// expanding stats to get the number of users in the group
+	query, err := applyListOpts(c.GroupAPI.ListGroups(ctx).Expand("stats"), opts...)
+	if err != nil {
+		return nil, err
+	}
+	for group, err := range All(ctx, query.Search(filter)) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to query okta group: %w", err)
+		}
+		groups = append(groups, group)
+	}
 	return groups, nil
 }
 
+func groupName(group okta.Group) string {
+	if group.Profile == nil {
+		return ""
+	}
+	return group.Profile.GetName()
+}
+
 func buildFilterNameBatches(groupNames []string, batchsize int) []string {
 	batches := []string{}
 	for i := 0; i < len(groupNames); i += batchsize {
@@ -132,30 +439,9 @@ func buildFilterNameBatches(groupNames []string, batchsize int) []string {
 }
 
 func toFilterString(groupNames []string) string {
-	if len(groupNames) == 0 {
-		return ""
+	exprs := make([]Expr, 0, len(groupNames))
+	for _, name := range groupNames {
+		exprs = append(exprs, Eq("profile.name", name))
 	}
-	return fmt.Sprintf("profile.name eq \"%s\"", strings.Join(groupNames, "\" or profile.name eq \""))
-}
-
-func jwkFromBytes(bytes []byte) (*jose.JSONWebKey, error) {
-	jwk := &jose.JSONWebKey{}
-	if err := json.Unmarshal(bytes, jwk); err != nil {
-		return nil, fmt.Errorf("failed to marhsal jwk bytes to json: %w", err)
-	}
-	return jwk, nil
-}
-
-func jwkToRSA(jwk *jose.JSONWebKey) (string, error) {
-	rsaPrivateKey, ok := jwk.Key.(*rsa.PrivateKey)
-	if !ok {
-		return "", fmt.Errorf("key data must be of type *rsa.PrivateKey")
-	}
-
-	pemKey := &strings.Builder{}
-	if err := pem.Encode(pemKey, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaPrivateKey)}); err != nil {
-		return "", fmt.Errorf("failed to marshal pkcs1 private key: %w", err)
-	}
-
-	return pemKey.String(), nil
+	return Or(exprs...).String()
 }
@@ -1,11 +1,80 @@
 package okta
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+var (
+	testKeyOnce sync.Once
+	testKeyPEM  string
+)
+
+// testPrivateKey returns a PKCS1 PEM encoded RSA key shared by all tests in the package.
+func testPrivateKey(t *testing.T) string {
+	testKeyOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		testKeyPEM = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	})
+	return testKeyPEM
+}
+
+// newTestClient starts an httptest server that issues access tokens and delegates all
+// other requests to handler, and returns a Client configured to use it.
+func newTestClient(t *testing.T, handler http.Handler, opts ...ClientOpt) (*Client, *httptest.Server) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"token_type":"Bearer","access_token":"test-token","expires_in":3600}`))
+	})
+	mux.Handle("/", handler)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewClientWithOptions(server.URL, "test-client-id", testPrivateKey(t), append([]ClientOpt{WithScopes("okta.groups.read")}, opts...)...)
+	require.NoError(t, err)
+
+	return client, server
+}
+
+// newSeededClient starts an oktatest server seeded with fixtures and returns a Client configured to use it.
+// Requests are not retried so injected faults are returned immediately.
+func newSeededClient(t *testing.T, fixtures oktatest.Fixtures, opts ...ClientOpt) (*Client, *oktatest.Server) {
+	t.Helper()
+
+	server := oktatest.NewTestServer(t, fixtures)
+	client, err := NewClientWithOptions(server.URL, "client-id", server.PrivateKey(), append([]ClientOpt{WithMaxRetries(0)}, opts...)...)
+	require.NoError(t, err)
+
+	return client, server
+}
+
+func TestNewClientKeepsPort(t *testing.T) {
+	client, err := NewClient("http://127.0.0.1:8443", "test-client-id", testPrivateKey(t))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:8443", client.GetConfig().Host)
+	assert.Equal(t, "http", client.GetConfig().Scheme)
+
+	client, err = NewClient("https://example.okta.com", "test-client-id", testPrivateKey(t))
+	require.NoError(t, err)
+	assert.Equal(t, "example.okta.com", client.GetConfig().Host)
+}
+
 func TestToFilterStringWithGroupNames(t *testing.T) {
 
 	tests := []struct {
@@ -61,3 +130,69 @@ func TestBuildFilterNameBatches(t *testing.T) {
 		})
 	}
 }
+
+// inflightTransport records the largest number of concurrent requests it has seen.
+type inflightTransport struct {
+	mu       sync.Mutex
+	inflight int
+	max      int
+}
+
+func (t *inflightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.inflight++
+	t.max = max(t.max, t.inflight)
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		t.inflight--
+		t.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestGroupsByNameConcurrent(t *testing.T) {
+	groups := []oktatest.Group{}
+	names := []string{}
+	for i := range 20 {
+		name := fmt.Sprintf("group-%02d", i)
+		groups = append(groups, oktatest.Group{ID: fmt.Sprintf("g%02d", i), Name: name})
+		names = append(names, name)
+	}
+	transport := &inflightTransport{}
+	client, _ := newSeededClient(t, oktatest.Fixtures{Groups: groups}, WithBaseTransport(transport))
+
+	// request in reverse order with duplicates and names that do not exist
+	requested := []string{"missing-1"}
+	for i := len(names) - 1; i >= 0; i-- {
+		requested = append(requested, names[i])
+	}
+	requested = append(requested, "GROUP-05", "group-10", "missing-2")
+
+	result, err := client.GroupsByName(context.Background(), requested, 3, WithConcurrency(4))
+
+	var notFound *GroupsNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, []string{"missing-1", "missing-2"}, notFound.Names)
+
+	expected := []string{}
+	for i := len(names) - 1; i >= 0; i-- {
+		expected = append(expected, fmt.Sprintf("g%02d", i))
+	}
+	assert.Equal(t, expected, groupIDs(result))
+
+	assert.LessOrEqual(t, transport.max, 4)
+	assert.Greater(t, transport.max, 1)
+}
+
+func TestGroupsByNameConcurrentError(t *testing.T) {
+	client, server := newSeededClient(t, oktatest.Fixtures{Groups: []oktatest.Group{{ID: "g1", Name: "admins"}}})
+	server.InjectFault(oktatest.Fault{Method: http.MethodGet, PathPrefix: "/api/v1/groups", Status: http.StatusForbidden, Times: 1})
+
+	result, err := client.GroupsByName(context.Background(), []string{"admins", "a", "b", "c"}, 1, WithConcurrency(2))
+	assert.ErrorContains(t, err, "failed to query okta group")
+	assert.Nil(t, result)
+}
@@ -0,0 +1,180 @@
+package okta
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// SortOrder is the direction results are sorted in by WithSort.
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "asc"
+	SortDescending SortOrder = "desc"
+)
+
+type listOptions struct {
+	limit     *int32
+	after     *string
+	expand    *string
+	query     *string
+	search    *string
+	filter    *string
+	sortBy    *string
+	sortOrder *string
+}
+
+// ListOpt sets a request parameter on the client's list methods: GroupUsers, Users, Groups,
+// SearchUsers, SearchGroups, ListApplications and ListAppGroups. Okta does not support every parameter on
+// every endpoint, e.g. group members cannot be filtered, so passing an option the endpoint does not support
+// returns an error instead of silently listing more than was asked for.
+/*
+
+Example usage:
+
+for user, err := range client.Users(ctx,
+	okta.WithSearch(okta.Eq("status", "ACTIVE")),
+	okta.WithSort("profile.lastName", okta.SortAscending),
+	okta.WithPageSize(200),
+) {
+	...
+}
+*/
+type ListOpt func(*listOptions)
+
+// WithPageSize sets the number of results requested per page, the client follows the pages until all results are listed.
+func WithPageSize(size int32) ListOpt {
+	return func(o *listOptions) {
+		o.limit = &size
+	}
+}
+
+// WithAfter starts listing after the cursor, taken from the after parameter of a previous response's next link.
+func WithAfter(cursor string) ListOpt {
+	return func(o *listOptions) {
+		o.after = &cursor
+	}
+}
+
+// WithExpand embeds related resources in the results, e.g. "stats" for groups or "group" for app group assignments.
+func WithExpand(expand ...string) ListOpt {
+	return func(o *listOptions) {
+		value := strings.Join(expand, ",")
+		o.expand = &value
+	}
+}
+
+// WithQuery lists the resources whose name, or the endpoint's equivalent, starts with q.
+func WithQuery(q string) ListOpt {
+	return func(o *listOptions) {
+		o.query = &q
+	}
+}
+
+// WithSearch lists the resources matching the search expression.
+func WithSearch(expr Expr) ListOpt {
+	return func(o *listOptions) {
+		value := expr.String()
+		o.search = &value
+	}
+}
+
+// WithFilter lists the resources matching the filter expression. Filters support fewer attributes
+// and operators than WithSearch, see the Okta API reference of each endpoint.
+func WithFilter(expr Expr) ListOpt {
+	return func(o *listOptions) {
+		value := expr.String()
+		o.filter = &value
+	}
+}
+
+// WithSort sorts the results by the attribute. Okta only sorts search results, so it must be used with WithSearch.
+func WithSort(attribute string, order SortOrder) ListOpt {
+	return func(o *listOptions) {
+		o.sortBy = &attribute
+		if order != "" {
+			value := string(order)
+			o.sortOrder = &value
+		}
+	}
+}
+
+// applyListOpts sets the options on an SDK list request, returning an error for options the request does not support.
+func applyListOpts[R any](req R, opts ...ListOpt) (R, error) {
+	o := &listOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	unsupported := func(param string) error {
+		return fmt.Errorf("okta list option %s is not supported by %T", param, req)
+	}
+
+	if o.limit != nil {
+		r, ok := any(req).(interface{ Limit(int32) R })
+		if !ok {
+			return req, unsupported("limit")
+		}
+		req = r.Limit(*o.limit)
+	}
+	if o.after != nil {
+		r, ok := any(req).(interface{ After(string) R })
+		if !ok {
+			return req, unsupported("after")
+		}
+		req = r.After(*o.after)
+	}
+	if o.expand != nil {
+		r, ok := any(req).(interface{ Expand(string) R })
+		if !ok {
+			return req, unsupported("expand")
+		}
+		req = r.Expand(*o.expand)
+	}
+	if o.query != nil {
+		r, ok := any(req).(interface{ Q(string) R })
+		if !ok {
+			return req, unsupported("q")
+		}
+		req = r.Q(*o.query)
+	}
+	if o.search != nil {
+		r, ok := any(req).(interface{ Search(string) R })
+		if !ok {
+			return req, unsupported("search")
+		}
+		req = r.Search(*o.search)
+	}
+	if o.filter != nil {
+		r, ok := any(req).(interface{ Filter(string) R })
+		if !ok {
+			return req, unsupported("filter")
+		}
+		req = r.Filter(*o.filter)
+	}
+	if o.sortBy != nil {
+		r, ok := any(req).(interface{ SortBy(string) R })
+		if !ok {
+			return req, unsupported("sortBy")
+		}
+		req = r.SortBy(*o.sortBy)
+	}
+	if o.sortOrder != nil {
+		r, ok := any(req).(interface{ SortOrder(string) R })
+		if !ok {
+			return req, unsupported("sortOrder")
+		}
+		req = r.SortOrder(*o.sortOrder)
+	}
+
+	return req, nil
+}
+
+// errSeq yields err as the only element of a sequence.
+func errSeq[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
@@ -0,0 +1,181 @@
+package okta
+
+import (
+	"context"
+	"iter"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newListServer(t *testing.T) *Client {
+	client, _ := newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com", LastName: "Smith"},
+			{ID: "u2", Login: "bob@example.com", LastName: "Jones", Status: "SUSPENDED"},
+			{ID: "u3", Login: "carol@example.com", LastName: "Brown"},
+			{ID: "u4", Login: "dave@example.com", LastName: "Adams"},
+		},
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: "admins"},
+			{ID: "g2", Name: "developers"},
+			{ID: "g3", Name: "devops"},
+		},
+		Members: map[string][]string{"developers": {"u1", "u2", "u3"}},
+		Apps:    []oktatest.App{{ID: "app1", Label: "Inventory"}},
+	})
+
+	return client
+}
+
+// collectIDs returns the ids of the items in seq, or the first error.
+func collectIDs[T any, P interface {
+	*T
+	GetId() string
+}](seq iter.Seq2[T, error]) ([]string, error) {
+	ids := []string{}
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, P(&item).GetId())
+	}
+	return ids, nil
+}
+
+func TestListOpts(t *testing.T) {
+	client := newListServer(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		list func() ([]string, error)
+		want []string
+		err  string
+	}{
+		{
+			name: "users",
+			list: func() ([]string, error) { return collectIDs(client.Users(ctx, WithPageSize(1))) },
+			want: []string{"u1", "u2", "u3", "u4"},
+		},
+		{
+			name: "users after cursor",
+			list: func() ([]string, error) { return collectIDs(client.Users(ctx, WithAfter("u2"))) },
+			want: []string{"u3", "u4"},
+		},
+		{
+			name: "users search and sort",
+			list: func() ([]string, error) {
+				return collectIDs(client.Users(ctx, WithSearch(Eq("status", "ACTIVE")), WithSort("profile.lastName", SortAscending), WithPageSize(2)))
+			},
+			want: []string{"u4", "u3", "u1"},
+		},
+		{
+			name: "search users with options",
+			list: func() ([]string, error) {
+				return collectIDs(client.SearchUsers(ctx, Eq("status", "ACTIVE"), WithSort("profile.lastName", SortDescending)))
+			},
+			want: []string{"u1", "u3", "u4"},
+		},
+		{
+			name: "groups query",
+			list: func() ([]string, error) { return collectIDs(client.Groups(ctx, WithQuery("dev"))) },
+			want: []string{"g2", "g3"},
+		},
+		{
+			name: "groups filter",
+			list: func() ([]string, error) {
+				return collectIDs(client.Groups(ctx, WithFilter(Eq("profile.name", "admins"))))
+			},
+			want: []string{"g1"},
+		},
+		{
+			name: "group members search and sort",
+			list: func() ([]string, error) {
+				return collectIDs(client.GroupUsers(ctx, "g2", WithSearch(Eq("status", "ACTIVE")), WithSort("profile.lastName", SortAscending)))
+			},
+			want: []string{"u3", "u1"},
+		},
+		{
+			name: "group members filter unsupported",
+			list: func() ([]string, error) {
+				return collectIDs(client.GroupUsers(ctx, "g2", WithFilter(Eq("status", "ACTIVE"))))
+			},
+			err: "okta list option filter is not supported by okta.ApiListGroupUsersRequest",
+		},
+		{
+			name: "apps sort unsupported",
+			list: func() ([]string, error) {
+				_, err := client.ListApplications(ctx, WithSort("label", SortAscending))
+				return nil, err
+			},
+			err: "okta list option sortBy is not supported by okta.ApiListApplicationsRequest",
+		},
+		{
+			name: "app groups search unsupported",
+			list: func() ([]string, error) {
+				_, err := client.ListAppGroups(ctx, "app1", WithSearch(Eq("id", "g1")))
+				return nil, err
+			},
+			err: "okta list option search is not supported by okta.ApiListApplicationGroupAssignmentsRequest",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ids, err := test.list()
+			if test.err != "" {
+				assert.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, ids)
+		})
+	}
+}
+
+func TestGroupsByNameListOpts(t *testing.T) {
+	client := newListServer(t)
+	ctx := context.Background()
+
+	groups, err := client.GroupsByName(ctx, []string{"developers"}, 20)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Contains(t, groups[0].Embedded, "stats", "stats are expanded by default")
+
+	groups, err = client.GroupsByName(ctx, []string{"developers"}, 20, WithListOpts(WithExpand("app")))
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.NotContains(t, groups[0].Embedded, "stats")
+
+	// the names always replace the search parameter
+	groups, err = client.GroupsByName(ctx, []string{"admins"}, 20, WithListOpts(WithSearch(Eq("profile.name", "devops"))))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"g1"}, groupIDs(groups))
+}
+
+func TestListGroupUsersOpts(t *testing.T) {
+	client := newListServer(t)
+	ctx := context.Background()
+
+	opts := []ListGroupUsersOpt{
+		WithLimit(1),
+		func(r okta.ApiListGroupUsersRequest) okta.ApiListGroupUsersRequest {
+			return r.Search(`status eq "ACTIVE"`).SortBy("profile.lastName")
+		},
+	}
+	members, err := client.ListGroupUsers(ctx, "g2", opts...)
+	require.NoError(t, err)
+	ids := []string{}
+	for _, member := range members {
+		ids = append(ids, member.GetId())
+	}
+	assert.Equal(t, []string{"u3", "u1"}, ids)
+
+	members, err = NewCachedClient(client).ListGroupUsers(ctx, "g2", opts...)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+}
@@ -0,0 +1,218 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	log "github.com/sirupsen/logrus"
+)
+
+// GroupLookup resolves group membership from the Okta API, it is implemented by *Client and *CachedClient.
+type GroupLookup interface {
+	GroupByName(ctx context.Context, groupName string) (okta.Group, error)
+	ListGroupUsers(ctx context.Context, groupId string, opts ...ListGroupUsersOpt) ([]okta.GroupMember, error)
+}
+
+// PolicyDecision is the result of authorizing a request against a GroupPolicy.
+type PolicyDecision struct {
+	Allowed bool `json:"-"`
+	// Reason explains the decision
+	Reason string `json:"reason"`
+	// Pattern is the route pattern that matched the request, empty when no route matched
+	Pattern string `json:"pattern,omitempty"`
+	// RequiredGroups are the groups the matched route allows, membership in any of them is required
+	RequiredGroups []string `json:"requiredGroups,omitempty"`
+	// Group is the group that granted access
+	Group string `json:"-"`
+}
+
+// GroupPolicy authorizes requests by the Okta group membership of the user in the request's access token.
+// Routes are matched with http.ServeMux patterns, e.g. "GET /admin/" or "/reports/{id}", and requests that
+// do not match a route are denied unless WithAllowUnmatched is set, so a request that the application's router
+// matches case insensitively or without its trailing slash, e.g. /Admin/ or /reports/1/, is not allowed.
+// Membership is read from the token's groups claim and, when a GroupLookup is configured, looked up in Okta
+// for users the claim does not grant access to, since group claims are often filtered to a subset of the user's groups.
+/*
+
+Example usage:
+
+policy, err := okta.NewGroupPolicy(map[string][]string{
+	"GET /reports/":    {"finance", "admins"},
+	"/admin/":          {"admins"},
+}, okta.WithGroupLookup(okta.NewCachedClient(client)))
+
+handler := validator.Middleware(policy.Middleware(mux))
+*/
+type GroupPolicy struct {
+	mux            *http.ServeMux
+	routes         map[string][]string
+	lookup         GroupLookup
+	allowUnmatched bool
+}
+
+type GroupPolicyOpt func(*GroupPolicy)
+
+// WithGroupLookup looks up group membership in Okta when the token's groups claim does not include a
+// required group. Use a *CachedClient to avoid listing the group's members on every request.
+func WithGroupLookup(lookup GroupLookup) GroupPolicyOpt {
+	return func(p *GroupPolicy) {
+		p.lookup = lookup
+	}
+}
+
+// WithAllowUnmatched allows requests that do not match any route, which are denied by default.
+func WithAllowUnmatched(allow bool) GroupPolicyOpt {
+	return func(p *GroupPolicy) {
+		p.allowUnmatched = allow
+	}
+}
+
+// NewGroupPolicy creates a GroupPolicy from route patterns and the names of the groups allowed to access them.
+func NewGroupPolicy(routes map[string][]string, opts ...GroupPolicyOpt) (policy *GroupPolicy, err error) {
+	policy = &GroupPolicy{
+		mux:    http.NewServeMux(),
+		routes: map[string][]string{},
+	}
+	for _, opt := range opts {
+		opt(policy)
+	}
+
+	// ServeMux panics on invalid and conflicting patterns
+	defer func() {
+		if r := recover(); r != nil {
+			policy, err = nil, fmt.Errorf("invalid group policy route: %v", r)
+		}
+	}()
+
+	for pattern, groups := range routes {
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("group policy route %q must require at least one group", pattern)
+		}
+		policy.mux.Handle(pattern, http.NotFoundHandler())
+		policy.routes[pattern] = slices.Clone(groups)
+	}
+
+	return policy, nil
+}
+
+// Authorize decides whether the request is allowed. The request context must hold the claims of a
+// validated access token, see TokenValidator.Middleware. An error is returned when membership could
+// not be looked up in Okta.
+func (p *GroupPolicy) Authorize(r *http.Request) (PolicyDecision, error) {
+	_, pattern := p.mux.Handler(r)
+	groups, ok := p.routes[pattern]
+	if !ok {
+		return PolicyDecision{Allowed: p.allowUnmatched, Reason: "no group policy for route"}, nil
+	}
+
+	decision := PolicyDecision{Pattern: pattern, RequiredGroups: groups}
+
+	claims, ok := ClaimsFromContext(r.Context())
+	if !ok {
+		decision.Reason = "request has no access token claims"
+		return decision, nil
+	}
+
+	for _, group := range groups {
+		if claims.InGroup(group) {
+			decision.Allowed = true
+			decision.Group = group
+			decision.Reason = fmt.Sprintf("member of group %q", group)
+			return decision, nil
+		}
+	}
+
+	if p.lookup != nil {
+		group, err := p.lookupMembership(r.Context(), claims, groups)
+		if err != nil {
+			return decision, err
+		}
+		if group != "" {
+			decision.Allowed = true
+			decision.Group = group
+			decision.Reason = fmt.Sprintf("member of group %q", group)
+			return decision, nil
+		}
+	}
+
+	decision.Reason = "not a member of any required group"
+	return decision, nil
+}
+
+// lookupMembership returns the first of groups the token's user is a member of in Okta.
+// Users are matched by the uid claim or, for tokens without one, a sub claim holding the login.
+func (p *GroupPolicy) lookupMembership(ctx context.Context, claims *Claims, groups []string) (string, error) {
+	if claims.UserID == "" && claims.Subject == "" {
+		return "", nil
+	}
+
+	for _, name := range groups {
+		group, err := p.lookup.GroupByName(ctx, name)
+		if errors.Is(err, ErrGroupNotFound) {
+			log.Warnf("group policy requires okta group %q which does not exist", name)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		members, err := p.lookup.ListGroupUsers(ctx, group.GetId())
+		if err != nil {
+			return "", err
+		}
+
+		for _, member := range members {
+			if claims.UserID != "" {
+				if member.GetId() == claims.UserID {
+					return name, nil
+				}
+				continue
+			}
+			if member.Profile != nil && strings.EqualFold(member.Profile.GetLogin(), claims.Subject) {
+				return name, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// Middleware passes allowed requests to next. Requests without access token claims are rejected
+// with 401, denied requests with 403 and a JSON body with the reason, e.g.
+//
+//	{"error":"forbidden","reason":"not a member of any required group","pattern":"/admin/","requiredGroups":["admins"]}
+//
+// and 503 is returned when membership could not be looked up in Okta.
+func (p *GroupPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		decision, err := p.Authorize(r)
+		if err != nil {
+			log.Errorf("unable to look up okta group membership: %s", err)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		if !decision.Allowed {
+			status, code := http.StatusForbidden, "forbidden"
+			if _, ok := ClaimsFromContext(r.Context()); !ok {
+				status, code = http.StatusUnauthorized, "unauthorized"
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(struct {
+				Error string `json:"error"`
+				PolicyDecision
+			}{Error: code, PolicyDecision: decision})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
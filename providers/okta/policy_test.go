@@ -0,0 +1,225 @@
+package okta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicyRoutes = map[string][]string{
+	"GET /reports/{id}": {"finance", "admins"},
+	"/admin/":           {"admins"},
+	"/missing/":         {"does-not-exist"},
+}
+
+func newPolicyServer(t *testing.T) (*CachedClient, *oktatest.Server) {
+	client, server := newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com"},
+			{ID: "u2", Login: "bob@example.com"},
+		},
+		Groups:  []oktatest.Group{{ID: "g1", Name: "admins"}, {ID: "g2", Name: "finance"}},
+		Members: map[string][]string{"admins": {"u1"}, "finance": {"u2"}},
+	})
+
+	return NewCachedClient(client), server
+}
+
+func TestGroupPolicy(t *testing.T) {
+	client, server := newPolicyServer(t)
+	validator := NewTokenValidator(server.Issuer(), oktatest.DefaultAudience)
+
+	fromClaims, err := NewGroupPolicy(testPolicyRoutes)
+	require.NoError(t, err)
+	fromOkta, err := NewGroupPolicy(testPolicyRoutes, WithGroupLookup(client))
+	require.NoError(t, err)
+	allowUnmatched, err := NewGroupPolicy(testPolicyRoutes, WithAllowUnmatched(true))
+	require.NoError(t, err)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name     string
+		policy   *GroupPolicy
+		method   string
+		path     string
+		claims   map[string]any
+		status   int
+		decision map[string]any
+	}{
+		{
+			name:   "member from claims",
+			policy: fromClaims,
+			path:   "/admin/users",
+			claims: map[string]any{"sub": "alice@example.com", "groups": []string{"everyone", "admins"}},
+			status: http.StatusNoContent,
+		},
+		{
+			name:     "unmatched route",
+			policy:   fromClaims,
+			path:     "/public",
+			claims:   map[string]any{"sub": "alice@example.com"},
+			status:   http.StatusForbidden,
+			decision: map[string]any{"error": "forbidden", "reason": "no group policy for route"},
+		},
+		{
+			name:   "method not covered by route",
+			policy: fromClaims,
+			method: http.MethodPost,
+			path:   "/reports/1",
+			claims: map[string]any{"sub": "alice@example.com"},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "trailing slash not covered by route",
+			policy: fromClaims,
+			path:   "/reports/1/",
+			claims: map[string]any{"sub": "alice@example.com", "groups": []string{"everyone"}},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "case not covered by route",
+			policy: fromClaims,
+			path:   "/Admin/x",
+			claims: map[string]any{"sub": "alice@example.com", "groups": []string{"everyone"}},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "unmatched route allowed",
+			policy: allowUnmatched,
+			path:   "/public",
+			claims: map[string]any{"sub": "alice@example.com"},
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "matched route with unmatched allowed",
+			policy: allowUnmatched,
+			path:   "/admin/users",
+			claims: map[string]any{"sub": "alice@example.com", "groups": []string{"everyone"}},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "not a member from claims",
+			policy: fromClaims,
+			path:   "/reports/1",
+			claims: map[string]any{"sub": "alice@example.com", "uid": "u1", "groups": []string{"everyone"}},
+			status: http.StatusForbidden,
+			decision: map[string]any{
+				"error":          "forbidden",
+				"reason":         "not a member of any required group",
+				"pattern":        "GET /reports/{id}",
+				"requiredGroups": []any{"finance", "admins"},
+			},
+		},
+		{
+			name:   "member from okta by user id",
+			policy: fromOkta,
+			path:   "/reports/1",
+			claims: map[string]any{"sub": "alice@example.com", "uid": "u1", "groups": []string{"everyone"}},
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "member from okta by login",
+			policy: fromOkta,
+			path:   "/reports/1",
+			claims: map[string]any{"sub": "BOB@example.com"},
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "not a member in okta",
+			policy: fromOkta,
+			path:   "/admin/",
+			claims: map[string]any{"sub": "bob@example.com", "uid": "u2"},
+			status: http.StatusForbidden,
+			decision: map[string]any{
+				"error":          "forbidden",
+				"reason":         "not a member of any required group",
+				"pattern":        "/admin/",
+				"requiredGroups": []any{"admins"},
+			},
+		},
+		{
+			name:   "group missing in okta",
+			policy: fromOkta,
+			path:   "/missing/",
+			claims: map[string]any{"sub": "alice@example.com", "uid": "u1"},
+			status: http.StatusForbidden,
+			decision: map[string]any{
+				"error":          "forbidden",
+				"reason":         "not a member of any required group",
+				"pattern":        "/missing/",
+				"requiredGroups": []any{"does-not-exist"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+			req.Header.Set("Authorization", "Bearer "+server.AccessToken(test.claims))
+			rec := httptest.NewRecorder()
+			validator.Middleware(test.policy.Middleware(next)).ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			if test.decision != nil {
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+				decision := map[string]any{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decision))
+				assert.Equal(t, test.decision, decision)
+			}
+		})
+	}
+}
+
+func TestGroupPolicyCachesLookups(t *testing.T) {
+	client, server := newPolicyServer(t)
+
+	policy, err := NewGroupPolicy(testPolicyRoutes, WithGroupLookup(client))
+	require.NoError(t, err)
+
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		req = req.WithContext(ContextWithClaims(req.Context(), &Claims{Subject: "alice@example.com", UserID: "u1"}))
+
+		decision, err := policy.Authorize(req)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "admins", decision.Group)
+	}
+
+	assert.Equal(t, 1, countRequests(server, "GET /api/v1/groups/g1/users"))
+	assert.Equal(t, 2, countRequests(server, "GET /api/v1/groups"), "the group should be looked up once and its members listed once")
+}
+
+func TestGroupPolicyErrors(t *testing.T) {
+	client, server := newPolicyServer(t)
+
+	_, err := NewGroupPolicy(map[string][]string{"/admin/": {}})
+	assert.ErrorContains(t, err, "must require at least one group")
+
+	_, err = NewGroupPolicy(map[string][]string{"GET /{id}/x": {"a"}, "GET /x/{id}": {"b"}})
+	assert.ErrorContains(t, err, "invalid group policy route")
+
+	policy, err := NewGroupPolicy(testPolicyRoutes, WithGroupLookup(client))
+	require.NoError(t, err)
+
+	// requests must pass through TokenValidator.Middleware first
+	rec := httptest.NewRecorder()
+	policy.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"error":"unauthorized","reason":"request has no access token claims","pattern":"/admin/","requiredGroups":["admins"]}`, rec.Body.String())
+
+	server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/groups", Status: http.StatusInternalServerError})
+	req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+	req = req.WithContext(ContextWithClaims(req.Context(), &Claims{UserID: "u1"}))
+	rec = httptest.NewRecorder()
+	policy.Middleware(http.NotFoundHandler()).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,279 @@
+package okta
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitWindow is how long Okta's rate limit buckets last before they refill.
+const rateLimitWindow = time.Minute
+
+// oktaIDPattern matches the ids of Okta objects, e.g. 00u1ab2cd3EfGhIjK4x7.
+var oktaIDPattern = regexp.MustCompile(`^[0-9A-Za-z]{20}$`)
+
+// rateLimitBucket is the last known state of an Okta rate limit bucket.
+type rateLimitBucket struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// rateLimitTransport is an http.RoundTripper that tracks the X-Rate-Limit-* headers returned by Okta,
+// pauses requests to an endpoint whose bucket is close to exhaustion, and retries rate limited (429)
+// and server error (5xx) responses with jittered exponential backoff.
+type rateLimitTransport struct {
+	// base performs the actual HTTP requests
+	base       http.RoundTripper
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	threshold  int
+
+	mu sync.Mutex
+	// buckets are keyed by endpoint template, see endpointTemplate
+	buckets map[string]*rateLimitBucket
+
+	// now and sleep are replaced in tests
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func newRateLimitTransport(o *clientOptions) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:       newAuditTransport(o),
+		maxRetries: o.maxRetries,
+		minBackoff: o.minBackoff,
+		maxBackoff: o.maxBackoff,
+		threshold:  o.rateLimitThreshold,
+		buckets:    map[string]*rateLimitBucket{},
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+// RoundTrip waits for the endpoint's rate limit bucket if necessary, sends the request and retries it
+// when the response is retryable. A canceled request context aborts any wait immediately.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	endpoint := endpointTemplate(req.URL.Path)
+
+	if err := t.waitForBucket(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	attemptReq := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+		t.updateBucket(endpoint, resp)
+
+		if attempt >= t.maxRetries || !isRetryable(req, resp) {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				recordRateLimited(ctx, resp)
+			}
+			return resp, nil
+		}
+
+		wait := t.backoff(attempt, resp)
+		drainBody(resp.Body)
+
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+
+		// RoundTrippers must not modify the caller's request so retries use a clone with a fresh body
+		attemptReq = req.Clone(ctx)
+		if req.Body != nil && req.Body != http.NoBody {
+			if attemptReq.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// waitForBucket blocks until the bucket for endpoint resets when its remaining requests are at or below the threshold.
+// Otherwise it reserves one request from the bucket so concurrent callers share the remaining budget, which includes
+// callers that waited for the reset so they do not all send their requests at once.
+func (t *rateLimitTransport) waitForBucket(ctx context.Context, endpoint string) error {
+	if t.threshold < 0 {
+		return nil
+	}
+
+	for {
+		t.mu.Lock()
+		bucket, ok := t.buckets[endpoint]
+		if !ok {
+			t.mu.Unlock()
+			return nil
+		}
+
+		now := t.now()
+		if !now.Before(bucket.reset) {
+			if bucket.limit <= 0 {
+				delete(t.buckets, endpoint)
+				t.mu.Unlock()
+				return nil
+			}
+			// the bucket has refilled, assume a new window until a response reports the actual state
+			bucket.remaining = bucket.limit
+			bucket.reset = now.Add(rateLimitWindow)
+		}
+
+		if bucket.remaining > t.threshold {
+			bucket.remaining--
+			t.mu.Unlock()
+			return nil
+		}
+
+		wait := bucket.reset.Sub(now) + t.jitter(t.minBackoff)
+		t.mu.Unlock()
+
+		if err := t.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// updateBucket records the rate limit state reported by resp for endpoint and evicts buckets that have reset.
+func (t *rateLimitTransport) updateBucket(endpoint string, resp *http.Response) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	resetIn, ok := resetAfter(resp)
+	if !ok {
+		return
+	}
+
+	// the limit is optional, without it buckets are dropped rather than refilled once they reset
+	limit, _ := strconv.Atoi(resp.Header.Get("X-Rate-Limit-Limit"))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for key, bucket := range t.buckets {
+		if !now.Before(bucket.reset) {
+			delete(t.buckets, key)
+		}
+	}
+
+	t.buckets[endpoint] = &rateLimitBucket{
+		limit:     limit,
+		remaining: remaining,
+		reset:     now.Add(resetIn),
+	}
+}
+
+// endpointTemplate returns the endpoint of path as Okta scopes its rate limits, with the ids and logins
+// in the path replaced by a placeholder, e.g. /api/v1/users/{id} for /api/v1/users/00u1ab2cd3EfGhIjK4x7.
+func endpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, "@") || (oktaIDPattern.MatchString(segment) && strings.ContainsAny(segment, "0123456789")) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// backoff returns how long to wait before retrying after the given attempt.
+// Rate limited responses wait for the bucket to reset, everything else uses jittered exponential backoff.
+func (t *rateLimitTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if resetIn, ok := resetAfter(resp); ok {
+			return resetIn + t.jitter(t.minBackoff)
+		}
+	}
+
+	delay := t.minBackoff << attempt
+	if delay > t.maxBackoff || delay <= 0 {
+		delay = t.maxBackoff
+	}
+
+	// equal jitter keeps at least half of the delay while spreading out concurrent retries
+	return delay/2 + t.jitter(delay/2)
+}
+
+// jitter returns a random duration in [0, limit).
+func (t *rateLimitTransport) jitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
+// resetAfter returns the time until the rate limit bucket resets according to the X-Rate-Limit-Reset header.
+// The reset is relative to the server's Date header when present to avoid local clock skew.
+func resetAfter(resp *http.Response) (time.Duration, bool) {
+	reset, err := strconv.ParseInt(resp.Header.Get("X-Rate-Limit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	serverNow := time.Now()
+	if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		serverNow = date
+	}
+
+	resetIn := time.Unix(reset, 0).Sub(serverNow)
+	if resetIn < 0 {
+		resetIn = 0
+	}
+	return resetIn, true
+}
+
+// isRetryable reports whether the request can be safely sent again after receiving resp.
+// Rate limited requests were never processed so they are always retried, server errors are only
+// retried for idempotent methods. Requests with a body that cannot be rewound are never retried.
+func isRetryable(req *http.Request, resp *http.Response) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+			return true
+		}
+	}
+
+	return false
+}
+
+func drainBody(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 4096))
+	_ = body.Close()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
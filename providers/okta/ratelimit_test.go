@@ -0,0 +1,318 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRateLimitTransport returns a transport with fast backoffs that records requested sleeps instead of sleeping.
+func newTestRateLimitTransport(sleeps *[]time.Duration, opts ...ClientOpt) *rateLimitTransport {
+	options := defaultClientOptions()
+	options.minBackoff = time.Millisecond
+	options.maxBackoff = 10 * time.Millisecond
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	rt := newRateLimitTransport(options)
+
+	// sleeps advance the transport's clock so waits for a bucket reset complete
+	var mu sync.Mutex
+	var elapsed time.Duration
+	rt.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return time.Now().Add(elapsed)
+	}
+	rt.sleep = func(ctx context.Context, d time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+		*sleeps = append(*sleeps, d)
+		elapsed += d
+		return ctx.Err()
+	}
+	return rt
+}
+
+// statusSequenceServer responds with each status in turn and 200 once the sequence is exhausted.
+func statusSequenceServer(t *testing.T, requests *atomic.Int32, statuses ...int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1)) - 1
+		status := http.StatusOK
+		if n < len(statuses) {
+			status = statuses[n]
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("X-Rate-Limit-Remaining", "0")
+			w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Add(2*time.Second).Unix(), 10))
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRateLimitTransportRetries(t *testing.T) {
+	tests := map[string]struct {
+		method     string
+		statuses   []int
+		maxRetries int
+		wantStatus int
+		wantCalls  int32
+	}{
+		"success": {
+			method:     http.MethodGet,
+			maxRetries: 3,
+			wantStatus: http.StatusOK,
+			wantCalls:  1,
+		},
+		"retry rate limited": {
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusTooManyRequests},
+			maxRetries: 3,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+		"retry server errors": {
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+			maxRetries: 3,
+			wantStatus: http.StatusOK,
+			wantCalls:  3,
+		},
+		"give up after max retries": {
+			method:     http.MethodGet,
+			statuses:   []int{500, 500, 500, 500},
+			maxRetries: 2,
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  3,
+		},
+		"retries disabled": {
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusTooManyRequests},
+			maxRetries: 0,
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+		"no retry for client errors": {
+			method:     http.MethodGet,
+			statuses:   []int{http.StatusNotFound},
+			maxRetries: 3,
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+		"no retry for non-idempotent server errors": {
+			method:     http.MethodPost,
+			statuses:   []int{http.StatusInternalServerError},
+			maxRetries: 3,
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  1,
+		},
+		"retry rate limited non-idempotent": {
+			method:     http.MethodPost,
+			statuses:   []int{http.StatusTooManyRequests},
+			maxRetries: 3,
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := &atomic.Int32{}
+			server := statusSequenceServer(t, requests, test.statuses...)
+
+			sleeps := []time.Duration{}
+			rt := newTestRateLimitTransport(&sleeps, WithMaxRetries(test.maxRetries))
+
+			req, err := http.NewRequest(test.method, server.URL+"/api/v1/groups", strings.NewReader(`{}`))
+			require.NoError(t, err)
+
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.wantStatus, resp.StatusCode)
+			assert.Equal(t, test.wantCalls, requests.Load())
+			assert.Len(t, sleeps, int(test.wantCalls)-1)
+		})
+	}
+}
+
+func TestRateLimitTransportWaitsForReset(t *testing.T) {
+	requests := &atomic.Int32{}
+	server := statusSequenceServer(t, requests, http.StatusTooManyRequests)
+
+	sleeps := []time.Duration{}
+	rt := newTestRateLimitTransport(&sleeps)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/groups", nil)
+	require.NoError(t, err)
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// the retry waits for the bucket to reset rather than the exponential backoff
+	require.Len(t, sleeps, 1)
+	assert.Greater(t, sleeps[0], time.Second)
+}
+
+func TestRateLimitTransportProactivePause(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second)
+	remaining := &atomic.Int32{}
+	remaining.Store(3)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(int(remaining.Add(-1))))
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}))
+	t.Cleanup(server.Close)
+
+	sleeps := []time.Duration{}
+	rt := newTestRateLimitTransport(&sleeps, WithRateLimitThreshold(1))
+
+	for range 3 {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/groups", nil)
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// remaining is 2 after the first request and 1 after the second, so only the third request waits
+	require.Len(t, sleeps, 1)
+	assert.Greater(t, sleeps[0], 25*time.Second)
+
+	// a different endpoint uses a different bucket
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/users", nil)
+	require.NoError(t, err)
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, sleeps, 1)
+}
+
+func TestRateLimitTransportContextCanceled(t *testing.T) {
+	requests := &atomic.Int32{}
+	server := statusSequenceServer(t, requests, http.StatusTooManyRequests, http.StatusTooManyRequests)
+
+	options := defaultClientOptions()
+	rt := newRateLimitTransport(options)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/groups", nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = rt.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.EqualValues(t, 1, requests.Load())
+}
+
+func TestClientRetriesRateLimitedRequests(t *testing.T) {
+	requests := &atomic.Int32{}
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("X-Rate-Limit-Remaining", "0")
+			w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"user1"}]`))
+	}), WithBackoff(time.Millisecond, 10*time.Millisecond))
+
+	users, err := client.ListGroupUsers(context.Background(), "group")
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.EqualValues(t, 2, requests.Load())
+}
+
+func TestEndpointTemplate(t *testing.T) {
+	tests := map[string]string{
+		"/api/v1/groups":                                               "/api/v1/groups",
+		"/api/v1/users/00u1ab2cd3EfGhIjK4x7":                           "/api/v1/users/{id}",
+		"/api/v1/users/alice@example.com":                              "/api/v1/users/{id}",
+		"/api/v1/groups/00g1ab2cd3EfGhIjK4x7/users/00u1ab2cd3EfGh":     "/api/v1/groups/{id}/users/00u1ab2cd3EfGh",
+		"/api/v1/apps/0oa1ab2cd3EfGhIjK4x7/users/00u1ab2cd3EfGhIjK4x7": "/api/v1/apps/{id}/users/{id}",
+		"/api/v1/users/00u1ab2cd3EfGhIjK4x7/lifecycle/deactivate":      "/api/v1/users/{id}/lifecycle/deactivate",
+		"/api/v1/applicationcredentialsxy":                             "/api/v1/applicationcredentialsxy",
+	}
+
+	for path, want := range tests {
+		assert.Equal(t, want, endpointTemplate(path), path)
+	}
+}
+
+func TestRateLimitTransportBucketsByEndpoint(t *testing.T) {
+	reset := time.Now().Add(30 * time.Second)
+	remaining := &atomic.Int32{}
+	remaining.Store(3)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Remaining", strconv.Itoa(int(remaining.Add(-1))))
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	}))
+	t.Cleanup(server.Close)
+
+	sleeps := []time.Duration{}
+	rt := newTestRateLimitTransport(&sleeps, WithRateLimitThreshold(1))
+
+	for _, id := range []string{"00u1ab2cd3EfGhIjK4x1", "00u1ab2cd3EfGhIjK4x2", "00u1ab2cd3EfGhIjK4x3"} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/users/"+id, nil)
+		require.NoError(t, err)
+		resp, err := rt.RoundTrip(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// requests for different users share the /api/v1/users/{id} bucket
+	require.Len(t, sleeps, 1)
+	assert.Len(t, rt.buckets, 1)
+	assert.Contains(t, rt.buckets, "/api/v1/users/{id}")
+}
+
+func TestRateLimitTransportReservesAfterReset(t *testing.T) {
+	sleeps := []time.Duration{}
+	rt := newTestRateLimitTransport(&sleeps, WithRateLimitThreshold(1))
+	rt.buckets["/api/v1/groups"] = &rateLimitBucket{limit: 3, remaining: 1, reset: rt.now().Add(10 * time.Second)}
+
+	for range 3 {
+		require.NoError(t, rt.waitForBucket(context.Background(), "/api/v1/groups"))
+	}
+
+	// the first caller waits for the reset and reserves from the refilled bucket, which leaves a single
+	// request above the threshold so the third caller waits for the next window
+	require.Len(t, sleeps, 2)
+	assert.GreaterOrEqual(t, sleeps[0], 10*time.Second)
+	assert.GreaterOrEqual(t, sleeps[1], 50*time.Second)
+	assert.Equal(t, 2, rt.buckets["/api/v1/groups"].remaining)
+}
+
+func TestRateLimitTransportEvictsResetBuckets(t *testing.T) {
+	sleeps := []time.Duration{}
+	rt := newTestRateLimitTransport(&sleeps)
+	rt.buckets["/api/v1/groups"] = &rateLimitBucket{limit: 100, remaining: 50, reset: rt.now().Add(-time.Second)}
+
+	resp := &http.Response{Header: http.Header{}}
+	resp.Header.Set("X-Rate-Limit-Remaining", "10")
+	resp.Header.Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+	rt.updateBucket("/api/v1/users", resp)
+
+	assert.NotContains(t, rt.buckets, "/api/v1/groups")
+	assert.Contains(t, rt.buckets, "/api/v1/users")
+}
@@ -0,0 +1,165 @@
+package okta
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MembershipChange identifies a user that is added to or removed from a group.
+type MembershipChange struct {
+	UserID string `json:"userId"`
+	Login  string `json:"login,omitempty"`
+}
+
+// MembershipPlan describes the changes required to make a group contain exactly the desired users.
+type MembershipPlan struct {
+	GroupID   string `json:"groupId"`
+	GroupName string `json:"groupName"`
+	// Add are the desired users that are not currently members, sorted by login
+	Add []MembershipChange `json:"add"`
+	// Remove are the current members that are not desired, sorted by login
+	Remove []MembershipChange `json:"remove"`
+	// Unchanged is the number of desired users that are already members
+	Unchanged int `json:"unchanged"`
+	// DryRun is true when the plan was computed without modifying the group
+	DryRun bool `json:"dryRun"`
+}
+
+// HasChanges reports whether applying the plan modifies the group.
+func (p *MembershipPlan) HasChanges() bool {
+	return len(p.Add) > 0 || len(p.Remove) > 0
+}
+
+type reconcileOptions struct {
+	dryRun bool
+}
+
+type ReconcileOpt func(*reconcileOptions)
+
+// WithDryRun computes the plan without making any of its changes.
+func WithDryRun(dryRun bool) ReconcileOpt {
+	return func(o *reconcileOptions) {
+		o.dryRun = dryRun
+	}
+}
+
+// ReconcileGroupMembers makes the group named groupName contain exactly the desired users, which may be
+// given as Okta user ids or logins. The returned plan lists the users that were added and removed,
+// or would be when WithDryRun is set. When applying the plan fails part way the plan is returned
+// along with the error so callers can see what was attempted.
+func (c *Client) ReconcileGroupMembers(ctx context.Context, groupName string, desired []string, opts ...ReconcileOpt) (*MembershipPlan, error) {
+	options := &reconcileOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ctx = withRateLimitRecorder(ctx)
+
+	group, err := c.GroupByName(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := c.ListGroupUsers(ctx, group.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &MembershipPlan{
+		GroupID:   group.GetId(),
+		GroupName: groupName,
+		Add:       []MembershipChange{},
+		Remove:    []MembershipChange{},
+		DryRun:    options.dryRun,
+	}
+
+	// members are indexed by both id and login since either may be requested
+	current := map[string]MembershipChange{}
+	for _, member := range members {
+		change := MembershipChange{UserID: member.GetId()}
+		if member.Profile != nil {
+			change.Login = member.Profile.GetLogin()
+		}
+		current[change.UserID] = change
+		if change.Login != "" {
+			current[loginKey(change.Login)] = change
+		}
+	}
+
+	keep := map[string]bool{}
+	adding := map[string]bool{}
+	for _, user := range desired {
+		if member, ok := current[user]; ok {
+			keep[member.UserID] = true
+			continue
+		}
+		if member, ok := current[loginKey(user)]; ok {
+			keep[member.UserID] = true
+			continue
+		}
+
+		oktaUser, resp, err := c.UserAPI.GetUser(ctx, user).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			return nil, fmt.Errorf("failed to query okta user %q: %w", user, err)
+		}
+
+		change := MembershipChange{UserID: oktaUser.GetId()}
+		if oktaUser.Profile != nil {
+			change.Login = oktaUser.Profile.GetLogin()
+		}
+
+		// the user may be a member under a login alias that was not requested directly
+		if _, ok := current[change.UserID]; ok {
+			keep[change.UserID] = true
+			continue
+		}
+
+		if !adding[change.UserID] {
+			adding[change.UserID] = true
+			plan.Add = append(plan.Add, change)
+		}
+	}
+
+	for _, member := range members {
+		if !keep[member.GetId()] {
+			plan.Remove = append(plan.Remove, current[member.GetId()])
+		}
+	}
+	plan.Unchanged = len(keep)
+
+	sortMembershipChanges(plan.Add)
+	sortMembershipChanges(plan.Remove)
+
+	if options.dryRun {
+		return plan, nil
+	}
+
+	for _, change := range plan.Add {
+		resp, err := c.GroupAPI.AssignUserToGroup(ctx, plan.GroupID, change.UserID).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			return plan, fmt.Errorf("failed to add user %q to okta group %q: %w", change.UserID, groupName, err)
+		}
+	}
+
+	for _, change := range plan.Remove {
+		resp, err := c.GroupAPI.UnassignUserFromGroup(ctx, plan.GroupID, change.UserID).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			return plan, fmt.Errorf("failed to remove user %q from okta group %q: %w", change.UserID, groupName, err)
+		}
+	}
+
+	return plan, nil
+}
+
+// loginKey normalizes a login for comparison, Okta logins are case insensitive.
+func loginKey(login string) string {
+	return "login:" + strings.ToLower(login)
+}
+
+func sortMembershipChanges(changes []MembershipChange) {
+	slices.SortFunc(changes, func(a, b MembershipChange) int {
+		return cmp.Or(cmp.Compare(a.Login, b.Login), cmp.Compare(a.UserID, b.UserID))
+	})
+}
@@ -0,0 +1,115 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReconcileServer(t *testing.T) (*Client, *oktatest.Server) {
+	return newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com"},
+			{ID: "u2", Login: "bob@example.com"},
+			{ID: "u3", Login: "carol@example.com"},
+			{ID: "u4", Login: "dave@example.com"},
+		},
+		Groups:  []oktatest.Group{{ID: "group1", Name: "admins"}},
+		Members: map[string][]string{"admins": {"u1", "u2"}},
+	})
+}
+
+// mutations returns the membership changes the server received.
+func mutations(server *oktatest.Server) []string {
+	out := []string{}
+	for _, r := range server.Requests() {
+		if !strings.HasPrefix(r, http.MethodGet) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func TestReconcileGroupMembers(t *testing.T) {
+	tests := map[string]struct {
+		desired       []string
+		dryRun        bool
+		wantAdd       []MembershipChange
+		wantRemove    []MembershipChange
+		wantMembers   []string
+		wantMutations []string
+	}{
+		"no changes by id and login": {
+			desired:       []string{"u1", "BOB@example.com"},
+			wantAdd:       []MembershipChange{},
+			wantRemove:    []MembershipChange{},
+			wantMembers:   []string{"u1", "u2"},
+			wantMutations: []string{},
+		},
+		"add and remove": {
+			desired:       []string{"alice@example.com", "u4", "carol@example.com", "u4"},
+			wantAdd:       []MembershipChange{{UserID: "u3", Login: "carol@example.com"}, {UserID: "u4", Login: "dave@example.com"}},
+			wantRemove:    []MembershipChange{{UserID: "u2", Login: "bob@example.com"}},
+			wantMembers:   []string{"u1", "u3", "u4"},
+			wantMutations: []string{"PUT /api/v1/groups/group1/users/u3", "PUT /api/v1/groups/group1/users/u4", "DELETE /api/v1/groups/group1/users/u2"},
+		},
+		"dry run": {
+			desired:       []string{"u3"},
+			dryRun:        true,
+			wantAdd:       []MembershipChange{{UserID: "u3", Login: "carol@example.com"}},
+			wantRemove:    []MembershipChange{{UserID: "u1", Login: "alice@example.com"}, {UserID: "u2", Login: "bob@example.com"}},
+			wantMembers:   []string{"u1", "u2"},
+			wantMutations: []string{},
+		},
+		"empty desired removes everyone": {
+			desired:       []string{},
+			wantAdd:       []MembershipChange{},
+			wantRemove:    []MembershipChange{{UserID: "u1", Login: "alice@example.com"}, {UserID: "u2", Login: "bob@example.com"}},
+			wantMembers:   []string{},
+			wantMutations: []string{"DELETE /api/v1/groups/group1/users/u1", "DELETE /api/v1/groups/group1/users/u2"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client, server := newReconcileServer(t)
+
+			plan, err := client.ReconcileGroupMembers(context.Background(), "admins", test.desired, WithDryRun(test.dryRun))
+			require.NoError(t, err)
+
+			assert.Equal(t, "group1", plan.GroupID)
+			assert.Equal(t, test.dryRun, plan.DryRun)
+			assert.Equal(t, test.wantAdd, plan.Add)
+			assert.Equal(t, test.wantRemove, plan.Remove)
+			assert.Equal(t, len(test.wantAdd) > 0 || len(test.wantRemove) > 0, plan.HasChanges())
+			assert.Equal(t, test.wantMembers, server.Members("admins"))
+			assert.Equal(t, test.wantMutations, mutations(server))
+		})
+	}
+}
+
+func TestReconcileGroupMembersUnknownUser(t *testing.T) {
+	client, server := newReconcileServer(t)
+
+	plan, err := client.ReconcileGroupMembers(context.Background(), "admins", []string{"nobody@example.com"})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Nil(t, plan)
+	assert.Empty(t, mutations(server))
+}
+
+func TestReconcileGroupMembersAPIError(t *testing.T) {
+	client, server := newReconcileServer(t)
+	server.InjectFault(oktatest.Fault{Method: http.MethodPut, PathPrefix: "/api/v1/groups/group1/users", Status: http.StatusForbidden})
+
+	plan, err := client.ReconcileGroupMembers(context.Background(), "admins", []string{"u1", "u2", "u3"})
+	assert.ErrorIs(t, err, ErrForbidden)
+	require.NotNil(t, plan)
+	assert.Equal(t, []MembershipChange{{UserID: "u3", Login: "carol@example.com"}}, plan.Add)
+}
@@ -0,0 +1,363 @@
+package okta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"sigs.k8s.io/yaml"
+)
+
+var ErrOrgNotFound = errors.New("unable to find okta org")
+
+// OrgConfig is the configuration of a named Okta org in a Registry.
+// Exactly one of PrivateKey or JWK must be set.
+type OrgConfig struct {
+	Name     string `json:"name"`
+	OrgURL   string `json:"orgUrl"`
+	ClientID string `json:"clientId"`
+	// PrivateKey is a PEM encoded private key or a path to one, see NewClient
+	PrivateKey string `json:"privateKey,omitempty"`
+	// JWK is a JWK or JWK Set, see NewClientFromJWKBytes
+	JWK    string   `json:"jwk,omitempty"`
+	KeyID  string   `json:"keyId,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+func (c OrgConfig) validate() error {
+	switch {
+	case c.Name == "":
+		return errors.New("okta org name is required")
+	case c.OrgURL == "":
+		return fmt.Errorf("okta org %q: orgUrl is required", c.Name)
+	case c.ClientID == "":
+		return fmt.Errorf("okta org %q: clientId is required", c.Name)
+	case (c.PrivateKey == "") == (c.JWK == ""):
+		return fmt.Errorf("okta org %q: exactly one of privateKey or jwk is required", c.Name)
+	}
+	return nil
+}
+
+// ReadOrgConfigs reads org configurations from YAML or JSON in the form:
+/*
+
+orgs:
+  - name: prod
+    orgUrl: https://example.okta.com
+    clientId: 0oa...
+    privateKey: /etc/okta/prod.pem
+  - name: preview
+    orgUrl: https://example.oktapreview.com
+    clientId: 0oa...
+    jwk: '{"kty":"EC",...}'
+*/
+func ReadOrgConfigs(r io.Reader) ([]OrgConfig, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read okta org configs: %w", err)
+	}
+
+	var file struct {
+		Orgs []OrgConfig `json:"orgs"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal okta org configs: %w", err)
+	}
+	return file.Orgs, nil
+}
+
+// Registry holds the clients of several Okta orgs by name. Clients are created on first use,
+// so orgs that a process never queries do not need their keys to be loadable.
+/*
+
+Example usage:
+
+configs, err := okta.ReadOrgConfigs(file)
+registry, err := okta.NewRegistry(configs, okta.WithOrgClientOpts(okta.WithMaxRetries(3)))
+
+prod, err := registry.Client("prod")
+
+groups, err := registry.GroupByName(ctx, "admins")
+for _, group := range groups {
+	fmt.Println(group.Org, group.Value.GetId())
+}
+*/
+type Registry struct {
+	orgs       []string
+	configs    map[string]OrgConfig
+	clientOpts []ClientOpt
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+type RegistryOpt func(*Registry)
+
+// WithOrgClientOpts sets the options used to create the client of every org, the org's KeyID and Scopes are applied after them.
+func WithOrgClientOpts(opts ...ClientOpt) RegistryOpt {
+	return func(r *Registry) {
+		r.clientOpts = append(r.clientOpts, opts...)
+	}
+}
+
+// NewRegistry creates a Registry of the orgs, which must have unique names.
+// Queries that fan out across orgs return results in the order the orgs are configured.
+func NewRegistry(configs []OrgConfig, opts ...RegistryOpt) (*Registry, error) {
+	r := &Registry{
+		orgs:    []string{},
+		configs: map[string]OrgConfig{},
+		clients: map[string]*Client{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	for _, config := range configs {
+		if err := config.validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := r.configs[config.Name]; ok {
+			return nil, fmt.Errorf("okta org %q is configured more than once", config.Name)
+		}
+		r.orgs = append(r.orgs, config.Name)
+		r.configs[config.Name] = config
+	}
+
+	return r, nil
+}
+
+// Orgs returns the names of the orgs in the order they are configured.
+func (r *Registry) Orgs() []string {
+	return slices.Clone(r.orgs)
+}
+
+// Client returns the client of the named org, creating it on first use.
+// A client that fails to be created is retried on the next call.
+func (r *Registry) Client(org string) (*Client, error) {
+	config, ok := r.configs[org]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrOrgNotFound, org)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if client, ok := r.clients[org]; ok {
+		return client, nil
+	}
+
+	opts := slices.Clone(r.clientOpts)
+	if config.KeyID != "" {
+		opts = append(opts, WithKeyID(config.KeyID))
+	}
+	if len(config.Scopes) > 0 {
+		opts = append(opts, WithScopes(config.Scopes...))
+	}
+
+	var (
+		client *Client
+		err    error
+	)
+	if config.JWK != "" {
+		client, err = NewClientFromJWKBytesWithOptions(config.OrgURL, config.ClientID, []byte(config.JWK), opts...)
+	} else {
+		client, err = NewClientWithOptions(config.OrgURL, config.ClientID, config.PrivateKey, opts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for okta org %q: %w", org, err)
+	}
+
+	r.clients[org] = client
+	return client, nil
+}
+
+// OrgResult is a value returned by an org.
+type OrgResult[T any] struct {
+	Org   string `json:"org"`
+	Value T      `json:"value"`
+}
+
+// OrgError is the error an org returned while fanning out a query.
+type OrgError struct {
+	Org string
+	Err error
+}
+
+func (e *OrgError) Error() string {
+	return fmt.Sprintf("okta org %q: %s", e.Org, e.Err)
+}
+
+func (e *OrgError) Unwrap() error {
+	return e.Err
+}
+
+// FanOut runs query with the client of each of the orgs concurrently, or every org when none are given, and merges
+// the results tagged with their org in org order. Orgs that fail do not stop the others: their errors are
+// returned as *OrgError values joined with errors.Join, along with the results of the orgs that succeeded.
+/*
+
+Example usage:
+
+apps, err := okta.FanOut(ctx, registry, func(ctx context.Context, org string, client *okta.Client) ([]okta.Application, error) {
+	return client.ApplicationsByLabel(ctx, "Inventory")
+})
+*/
+func FanOut[T any](ctx context.Context, r *Registry, query func(ctx context.Context, org string, client *Client) ([]T, error), orgs ...string) ([]OrgResult[T], error) {
+	if len(orgs) == 0 {
+		orgs = r.orgs
+	}
+
+	results := make([][]T, len(orgs))
+	errs := make([]error, len(orgs))
+
+	var wg sync.WaitGroup
+	for i, org := range orgs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			client, err := r.Client(org)
+			if err == nil {
+				results[i], err = query(ctx, org, client)
+			}
+			if err != nil {
+				errs[i] = &OrgError{Org: org, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	merged := []OrgResult[T]{}
+	for i, org := range orgs {
+		for _, value := range results[i] {
+			merged = append(merged, OrgResult[T]{Org: org, Value: value})
+		}
+	}
+
+	return merged, errors.Join(errs...)
+}
+
+// GroupByName returns the group named groupName from each org it exists in.
+// ErrGroupNotFound is returned only when no org has the group.
+func (r *Registry) GroupByName(ctx context.Context, groupName string, orgs ...string) ([]OrgResult[okta.Group], error) {
+	groups, err := FanOut(ctx, r, func(ctx context.Context, _ string, client *Client) ([]okta.Group, error) {
+		group, err := client.GroupByName(ctx, groupName)
+		if errors.Is(err, ErrGroupNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []okta.Group{group}, nil
+	}, orgs...)
+
+	if err == nil && len(groups) == 0 {
+		return groups, fmt.Errorf("%w %q", ErrGroupNotFound, groupName)
+	}
+	return groups, err
+}
+
+// GroupsByName looks up the named groups in each org, or only in orgs when given, with Client.GroupsByName and opts.
+// Names that are missing from some orgs are not an error, a *GroupsNotFoundError is returned for the names that no org has.
+func (r *Registry) GroupsByName(ctx context.Context, groupNames []string, batchsize int, opts []GroupsByNameOpt, orgs ...string) ([]OrgResult[okta.Group], error) {
+	groups, err := FanOut(ctx, r, func(ctx context.Context, _ string, client *Client) ([]okta.Group, error) {
+		groups, err := client.GroupsByName(ctx, groupNames, batchsize, opts...)
+		if errors.Is(err, ErrGroupNotFound) {
+			return groups, nil
+		}
+		return groups, err
+	}, orgs...)
+	if err != nil {
+		return groups, err
+	}
+
+	found := map[string]bool{}
+	for _, group := range groups {
+		found[strings.ToLower(groupName(group.Value))] = true
+	}
+	missing := []string{}
+	for _, name := range groupNames {
+		if !found[strings.ToLower(name)] && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return groups, &GroupsNotFoundError{Names: missing}
+	}
+
+	return groups, nil
+}
+
+// GroupMembers lists the members of the group named groupName with Client.ListGroupUsers and opts, in each org
+// it exists in or only in orgs when given. Group ids differ between orgs so groups are looked up by name.
+func (r *Registry) GroupMembers(ctx context.Context, groupName string, opts []ListGroupUsersOpt, orgs ...string) ([]OrgResult[okta.GroupMember], error) {
+	groups, err := r.GroupByName(ctx, groupName, orgs...)
+	if len(groups) == 0 {
+		return nil, err
+	}
+
+	ids := map[string]string{}
+	found := []string{}
+	for _, group := range groups {
+		ids[group.Org] = group.Value.GetId()
+		found = append(found, group.Org)
+	}
+
+	members, membersErr := FanOut(ctx, r, func(ctx context.Context, org string, client *Client) ([]okta.GroupMember, error) {
+		return client.ListGroupUsers(ctx, ids[org], opts...)
+	}, found...)
+
+	return members, errors.Join(err, membersErr)
+}
+
+// User returns the user with the id or login from each org it exists in.
+// ErrUserNotFound is returned only when no org has the user.
+func (r *Registry) User(ctx context.Context, idOrLogin string, orgs ...string) ([]OrgResult[*okta.UserGetSingleton], error) {
+	users, err := FanOut(ctx, r, func(ctx context.Context, _ string, client *Client) ([]*okta.UserGetSingleton, error) {
+		user, err := client.User(ctx, idOrLogin)
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []*okta.UserGetSingleton{user}, nil
+	}, orgs...)
+
+	if err == nil && len(users) == 0 {
+		return users, fmt.Errorf("%w %q", ErrUserNotFound, idOrLogin)
+	}
+	return users, err
+}
+
+// SearchUsers returns the users matching the search expression in every org.
+func (r *Registry) SearchUsers(ctx context.Context, expr Expr, opts ...ListOpt) ([]OrgResult[okta.User], error) {
+	return FanOut(ctx, r, func(ctx context.Context, _ string, client *Client) ([]okta.User, error) {
+		return collect(client.SearchUsers(ctx, expr, opts...))
+	})
+}
+
+// SearchGroups returns the groups matching the search expression in every org.
+func (r *Registry) SearchGroups(ctx context.Context, expr Expr, opts ...ListOpt) ([]OrgResult[okta.Group], error) {
+	return FanOut(ctx, r, func(ctx context.Context, _ string, client *Client) ([]okta.Group, error) {
+		return collect(client.SearchGroups(ctx, expr, opts...))
+	})
+}
+
+// collect reads all the items of seq, or returns the first error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	items := []T{}
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,195 @@
+package okta
+
+import (
+	"context"
+	"crypto/elliptic"
+	"net/http"
+	"strings"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRegistryServers(t *testing.T) (*Registry, map[string]*oktatest.Server) {
+	servers := map[string]*oktatest.Server{
+		"prod": oktatest.NewTestServer(t, oktatest.Fixtures{
+			Users:   []oktatest.User{{ID: "p1", Login: "alice@example.com"}, {ID: "p2", Login: "bob@example.com"}},
+			Groups:  []oktatest.Group{{ID: "pg1", Name: "admins"}, {ID: "pg2", Name: "prod-operators"}},
+			Members: map[string][]string{"admins": {"p1", "p2"}},
+		}),
+		"preview": oktatest.NewTestServer(t, oktatest.Fixtures{
+			Users:   []oktatest.User{{ID: "v1", Login: "alice@example.com"}, {ID: "v2", Login: "carol@example.com"}},
+			Groups:  []oktatest.Group{{ID: "vg1", Name: "admins"}, {ID: "vg2", Name: "testers"}},
+			Members: map[string][]string{"admins": {"v2"}},
+		}),
+	}
+
+	registry, err := NewRegistry([]OrgConfig{
+		{Name: "prod", OrgURL: servers["prod"].URL, ClientID: "prod-client", PrivateKey: servers["prod"].PrivateKey()},
+		{Name: "preview", OrgURL: servers["preview"].URL, ClientID: "preview-client", JWK: string(jwkJSON(t, testECKey(t, elliptic.P256()), "preview-kid", ""))},
+	}, WithOrgClientOpts(WithMaxRetries(0)))
+	require.NoError(t, err)
+
+	return registry, servers
+}
+
+// orgIDs returns the "org/id" of each result.
+func orgIDs[T any, P interface {
+	*T
+	GetId() string
+}](results []OrgResult[T]) []string {
+	ids := []string{}
+	for _, result := range results {
+		ids = append(ids, result.Org+"/"+P(&result.Value).GetId())
+	}
+	return ids
+}
+
+func TestReadOrgConfigs(t *testing.T) {
+	configs, err := ReadOrgConfigs(strings.NewReader(`
+orgs:
+  - name: prod
+    orgUrl: https://example.okta.com
+    clientId: prod-client
+    privateKey: /etc/okta/prod.pem
+    scopes: [okta.groups.read]
+  - name: preview
+    orgUrl: https://example.oktapreview.com
+    clientId: preview-client
+    jwk: '{"kty":"EC"}'
+    keyId: preview-kid
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []OrgConfig{
+		{Name: "prod", OrgURL: "https://example.okta.com", ClientID: "prod-client", PrivateKey: "/etc/okta/prod.pem", Scopes: []string{"okta.groups.read"}},
+		{Name: "preview", OrgURL: "https://example.oktapreview.com", ClientID: "preview-client", JWK: `{"kty":"EC"}`, KeyID: "preview-kid"},
+	}, configs)
+
+	_, err = ReadOrgConfigs(strings.NewReader(`{"orgs": [{"name": "prod", "url": "https://example.okta.com"}]}`))
+	assert.ErrorContains(t, err, "failed to unmarshal okta org configs")
+}
+
+func TestNewRegistryErrors(t *testing.T) {
+	valid := OrgConfig{Name: "prod", OrgURL: "https://example.okta.com", ClientID: "client", PrivateKey: "key"}
+
+	tests := map[string]struct {
+		configs []OrgConfig
+		err     string
+	}{
+		"missing name":      {configs: []OrgConfig{{OrgURL: "https://example.okta.com"}}, err: "okta org name is required"},
+		"missing org url":   {configs: []OrgConfig{{Name: "prod", ClientID: "client", PrivateKey: "key"}}, err: `okta org "prod": orgUrl is required`},
+		"missing client id": {configs: []OrgConfig{{Name: "prod", OrgURL: "https://example.okta.com", PrivateKey: "key"}}, err: `okta org "prod": clientId is required`},
+		"missing key":       {configs: []OrgConfig{{Name: "prod", OrgURL: "https://example.okta.com", ClientID: "client"}}, err: `okta org "prod": exactly one of privateKey or jwk is required`},
+		"both keys":         {configs: []OrgConfig{{Name: "prod", OrgURL: "https://example.okta.com", ClientID: "client", PrivateKey: "key", JWK: "{}"}}, err: `okta org "prod": exactly one of privateKey or jwk is required`},
+		"duplicate name":    {configs: []OrgConfig{valid, valid}, err: `okta org "prod" is configured more than once`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewRegistry(test.configs)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestRegistryClient(t *testing.T) {
+	registry, err := NewRegistry([]OrgConfig{
+		{Name: "prod", OrgURL: "https://example.okta.com", ClientID: "client", PrivateKey: pkcs8PEM(t, testRSAKey(t))},
+		{Name: "broken", OrgURL: "https://example.okta.com", ClientID: "client", PrivateKey: "not a key"},
+	})
+	require.NoError(t, err, "clients are not created until they are used")
+	assert.Equal(t, []string{"prod", "broken"}, registry.Orgs())
+
+	client, err := registry.Client("prod")
+	require.NoError(t, err)
+	again, err := registry.Client("prod")
+	require.NoError(t, err)
+	assert.Same(t, client, again)
+
+	_, err = registry.Client("broken")
+	assert.ErrorContains(t, err, `failed to create client for okta org "broken"`)
+
+	_, err = registry.Client("partner")
+	assert.ErrorIs(t, err, ErrOrgNotFound)
+}
+
+func TestRegistryFanOut(t *testing.T) {
+	registry, _ := newRegistryServers(t)
+	ctx := context.Background()
+
+	groups, err := registry.GroupByName(ctx, "admins")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod/pg1", "preview/vg1"}, orgIDs(groups))
+
+	groups, err = registry.GroupByName(ctx, "testers")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"preview/vg2"}, orgIDs(groups))
+
+	groups, err = registry.GroupByName(ctx, "admins", "preview")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"preview/vg1"}, orgIDs(groups))
+
+	_, err = registry.GroupByName(ctx, "missing")
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	groups, err = registry.GroupsByName(ctx, []string{"prod-operators", "testers", "missing"}, 20, nil)
+	var notFound *GroupsNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, []string{"missing"}, notFound.Names)
+	assert.Equal(t, []string{"prod/pg2", "preview/vg2"}, orgIDs(groups))
+
+	groups, err = registry.GroupsByName(ctx, []string{"prod-operators", "testers"}, 20, nil, "preview")
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, []string{"prod-operators"}, notFound.Names)
+	assert.Equal(t, []string{"preview/vg2"}, orgIDs(groups))
+
+	members, err := registry.GroupMembers(ctx, "admins", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod/p1", "prod/p2", "preview/v2"}, orgIDs(members))
+
+	members, err = registry.GroupMembers(ctx, "admins", []ListGroupUsersOpt{WithLimit(1)}, "prod")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod/p1", "prod/p2"}, orgIDs(members))
+
+	_, err = registry.GroupMembers(ctx, "testers", nil, "prod")
+	assert.ErrorIs(t, err, ErrGroupNotFound)
+
+	users, err := registry.User(ctx, "alice@example.com")
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "prod", users[0].Org)
+	assert.Equal(t, "p1", users[0].Value.GetId())
+	assert.Equal(t, "preview", users[1].Org)
+	assert.Equal(t, "v1", users[1].Value.GetId())
+
+	_, err = registry.User(ctx, "dave@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	found, err := registry.SearchUsers(ctx, Sw("profile.login", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"preview/v2"}, orgIDs(found))
+}
+
+func TestRegistryFanOutErrors(t *testing.T) {
+	registry, servers := newRegistryServers(t)
+	ctx := context.Background()
+
+	servers["preview"].InjectFault(oktatest.Fault{PathPrefix: "/api/v1/groups", Status: http.StatusForbidden})
+
+	// the orgs that succeed still return their results
+	groups, err := registry.SearchGroups(ctx, Eq("profile.name", "admins"))
+	assert.Equal(t, []string{"prod/pg1"}, orgIDs(groups))
+
+	var orgErr *OrgError
+	require.ErrorAs(t, err, &orgErr)
+	assert.Equal(t, "preview", orgErr.Org)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	results, err := FanOut(ctx, registry, func(ctx context.Context, org string, client *Client) ([]string, error) {
+		return []string{org}, nil
+	}, "prod", "partner")
+	assert.ErrorIs(t, err, ErrOrgNotFound)
+	assert.Equal(t, []OrgResult[string]{{Org: "prod", Value: "prod"}}, results)
+}
@@ -0,0 +1,284 @@
+package okta
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"sigs.k8s.io/yaml"
+)
+
+// SnapshotFormat is the encoding of a written Snapshot.
+type SnapshotFormat string
+
+const (
+	SnapshotJSON SnapshotFormat = "json"
+	SnapshotYAML SnapshotFormat = "yaml"
+	// SnapshotCSV writes one row per group membership, groups without members have a row with empty member columns
+	SnapshotCSV SnapshotFormat = "csv"
+)
+
+var snapshotCSVHeader = []string{"group_id", "group_name", "user_id", "login", "status"}
+
+// Snapshot is a point in time export of groups and their members.
+// Groups are sorted by name and members by login so that snapshots of the same state are identical.
+type Snapshot struct {
+	GeneratedAt time.Time       `json:"generatedAt"`
+	Groups      []GroupSnapshot `json:"groups"`
+}
+
+type GroupSnapshot struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Members     []MemberSnapshot `json:"members"`
+}
+
+type MemberSnapshot struct {
+	ID     string `json:"id"`
+	Login  string `json:"login"`
+	Status string `json:"status,omitempty"`
+}
+
+// SnapshotGroups exports the groups and their members.
+/*
+
+Example usage:
+
+groups, err := client.GroupsByName(ctx, []string{"admins", "developers"}, 50)
+snapshot, err := client.SnapshotGroups(ctx, groups)
+err = snapshot.Write(os.Stdout, okta.SnapshotYAML)
+*/
+func (c *Client) SnapshotGroups(ctx context.Context, groups []okta.Group) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Groups:      []GroupSnapshot{},
+	}
+
+	for _, group := range groups {
+		members, err := c.ListGroupUsers(ctx, group.GetId())
+		if err != nil {
+			return nil, err
+		}
+
+		g := GroupSnapshot{
+			ID:      group.GetId(),
+			Name:    groupName(group),
+			Members: []MemberSnapshot{},
+		}
+		if group.Profile != nil {
+			g.Description = group.Profile.GetDescription()
+		}
+		for _, member := range members {
+			m := MemberSnapshot{ID: member.GetId(), Status: member.GetStatus()}
+			if member.Profile != nil {
+				m.Login = member.Profile.GetLogin()
+			}
+			g.Members = append(g.Members, m)
+		}
+		snapshot.Groups = append(snapshot.Groups, g)
+	}
+
+	snapshot.sort()
+	return snapshot, nil
+}
+
+// SnapshotSearch exports the groups matching the search expression and their members.
+func (c *Client) SnapshotSearch(ctx context.Context, expr Expr) (*Snapshot, error) {
+	groups := []okta.Group{}
+	for group, err := range c.SearchGroups(ctx, expr) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to query okta group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	return c.SnapshotGroups(ctx, groups)
+}
+
+func (s *Snapshot) sort() {
+	slices.SortFunc(s.Groups, func(a, b GroupSnapshot) int {
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+	})
+	for _, group := range s.Groups {
+		sortMembers(group.Members)
+	}
+}
+
+func sortMembers(members []MemberSnapshot) {
+	slices.SortFunc(members, func(a, b MemberSnapshot) int {
+		return cmp.Or(strings.Compare(strings.ToLower(a.Login), strings.ToLower(b.Login)), strings.Compare(a.ID, b.ID))
+	})
+}
+
+// Write encodes the snapshot to w in the format.
+func (s *Snapshot) Write(w io.Writer, format SnapshotFormat) error {
+	switch format {
+	case SnapshotJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(s); err != nil {
+			return fmt.Errorf("failed to write json snapshot: %w", err)
+		}
+	case SnapshotYAML:
+		data, err := yaml.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal yaml snapshot: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write yaml snapshot: %w", err)
+		}
+	case SnapshotCSV:
+		writer := csv.NewWriter(w)
+		rows := [][]string{snapshotCSVHeader}
+		for _, group := range s.Groups {
+			if len(group.Members) == 0 {
+				rows = append(rows, []string{group.ID, group.Name, "", "", ""})
+			}
+			for _, member := range group.Members {
+				rows = append(rows, []string{group.ID, group.Name, member.ID, member.Login, member.Status})
+			}
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return fmt.Errorf("failed to write csv snapshot: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported snapshot format %q", format)
+	}
+	return nil
+}
+
+// ReadSnapshot decodes a snapshot written by Snapshot.Write. CSV snapshots do not include
+// the generation time or group descriptions.
+func ReadSnapshot(r io.Reader, format SnapshotFormat) (*Snapshot, error) {
+	snapshot := &Snapshot{Groups: []GroupSnapshot{}}
+
+	switch format {
+	case SnapshotJSON, SnapshotYAML:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		// JSON is valid YAML
+		if err := yaml.Unmarshal(data, snapshot); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s snapshot: %w", format, err)
+		}
+	case SnapshotCSV:
+		rows, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv snapshot: %w", err)
+		}
+		if len(rows) == 0 || !slices.Equal(rows[0], snapshotCSVHeader) {
+			return nil, fmt.Errorf("csv snapshot must start with the header %q", strings.Join(snapshotCSVHeader, ","))
+		}
+
+		index := map[string]int{}
+		for _, row := range rows[1:] {
+			i, ok := index[row[0]]
+			if !ok {
+				i = len(snapshot.Groups)
+				index[row[0]] = i
+				snapshot.Groups = append(snapshot.Groups, GroupSnapshot{ID: row[0], Name: row[1], Members: []MemberSnapshot{}})
+			}
+			if row[2] != "" {
+				snapshot.Groups[i].Members = append(snapshot.Groups[i].Members, MemberSnapshot{ID: row[2], Login: row[3], Status: row[4]})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported snapshot format %q", format)
+	}
+
+	snapshot.sort()
+	return snapshot, nil
+}
+
+// SnapshotDiff lists the differences between two snapshots.
+type SnapshotDiff struct {
+	// AddedGroups are in the current snapshot only, with their members
+	AddedGroups []GroupSnapshot `json:"addedGroups"`
+	// RemovedGroups are in the previous snapshot only, with their members
+	RemovedGroups []GroupSnapshot `json:"removedGroups"`
+	// Changed are the groups in both snapshots whose members changed
+	Changed []MembershipDiff `json:"changed"`
+}
+
+// MembershipDiff lists the members added to and removed from a group.
+type MembershipDiff struct {
+	GroupID   string           `json:"groupId"`
+	GroupName string           `json:"groupName"`
+	Added     []MemberSnapshot `json:"added"`
+	Removed   []MemberSnapshot `json:"removed"`
+}
+
+// HasChanges reports whether the snapshots differ.
+func (d SnapshotDiff) HasChanges() bool {
+	return len(d.AddedGroups) > 0 || len(d.RemovedGroups) > 0 || len(d.Changed) > 0
+}
+
+// DiffSnapshots compares the groups and members of two snapshots by id, so renamed groups and
+// users are not reported as changes. The group names in the diff are those of the current snapshot.
+func DiffSnapshots(previous, current *Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		AddedGroups:   []GroupSnapshot{},
+		RemovedGroups: []GroupSnapshot{},
+		Changed:       []MembershipDiff{},
+	}
+
+	oldGroups := map[string]GroupSnapshot{}
+	for _, group := range previous.Groups {
+		oldGroups[group.ID] = group
+	}
+	newGroups := map[string]GroupSnapshot{}
+	for _, group := range current.Groups {
+		newGroups[group.ID] = group
+	}
+
+	for _, group := range previous.Groups {
+		if _, ok := newGroups[group.ID]; !ok {
+			diff.RemovedGroups = append(diff.RemovedGroups, group)
+		}
+	}
+
+	for _, group := range current.Groups {
+		before, ok := oldGroups[group.ID]
+		if !ok {
+			diff.AddedGroups = append(diff.AddedGroups, group)
+			continue
+		}
+
+		change := MembershipDiff{
+			GroupID:   group.ID,
+			GroupName: group.Name,
+			Added:     subtractMembers(group.Members, before.Members),
+			Removed:   subtractMembers(before.Members, group.Members),
+		}
+		if len(change.Added) > 0 || len(change.Removed) > 0 {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	return diff
+}
+
+// subtractMembers returns the members of a that are not in b.
+func subtractMembers(a, b []MemberSnapshot) []MemberSnapshot {
+	ids := map[string]bool{}
+	for _, member := range b {
+		ids[member.ID] = true
+	}
+
+	out := []MemberSnapshot{}
+	for _, member := range a {
+		if !ids[member.ID] {
+			out = append(out, member)
+		}
+	}
+	sortMembers(out)
+	return out
+}
@@ -0,0 +1,153 @@
+package okta
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSnapshotServer(t *testing.T) (*Client, *oktatest.Server) {
+	return newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "carol@example.com"},
+			{ID: "u2", Login: "alice@example.com"},
+			{ID: "u3", Login: "bob@example.com", Status: "SUSPENDED"},
+		},
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: "team-viewers"},
+			{ID: "g2", Name: "team-admins", Description: "Administrators"},
+			{ID: "g3", Name: "team-empty"},
+			{ID: "g4", Name: "other"},
+		},
+		Members: map[string][]string{
+			"team-viewers": {"u1", "u2", "u3"},
+			"team-admins":  {"u2"},
+			"other":        {"u1"},
+		},
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	client, _ := newSnapshotServer(t)
+
+	snapshot, err := client.SnapshotSearch(context.Background(), Sw("profile.name", "team-"))
+	require.NoError(t, err)
+
+	snapshot.GeneratedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, []GroupSnapshot{
+		{ID: "g2", Name: "team-admins", Description: "Administrators", Members: []MemberSnapshot{{ID: "u2", Login: "alice@example.com", Status: "ACTIVE"}}},
+		{ID: "g3", Name: "team-empty", Members: []MemberSnapshot{}},
+		{ID: "g1", Name: "team-viewers", Members: []MemberSnapshot{
+			{ID: "u2", Login: "alice@example.com", Status: "ACTIVE"},
+			{ID: "u3", Login: "bob@example.com", Status: "SUSPENDED"},
+			{ID: "u1", Login: "carol@example.com", Status: "ACTIVE"},
+		}},
+	}, snapshot.Groups)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, snapshot.Write(out, SnapshotCSV))
+	assert.Equal(t, `group_id,group_name,user_id,login,status
+g2,team-admins,u2,alice@example.com,ACTIVE
+g3,team-empty,,,
+g1,team-viewers,u2,alice@example.com,ACTIVE
+g1,team-viewers,u3,bob@example.com,SUSPENDED
+g1,team-viewers,u1,carol@example.com,ACTIVE
+`, out.String())
+
+	out.Reset()
+	require.NoError(t, snapshot.Write(out, SnapshotYAML))
+	assert.Equal(t, `generatedAt: "2024-01-02T03:04:05Z"
+groups:
+- description: Administrators
+  id: g2
+  members:
+  - id: u2
+    login: alice@example.com
+    status: ACTIVE
+  name: team-admins
+- id: g3
+  members: []
+  name: team-empty
+- id: g1
+  members:
+  - id: u2
+    login: alice@example.com
+    status: ACTIVE
+  - id: u3
+    login: bob@example.com
+    status: SUSPENDED
+  - id: u1
+    login: carol@example.com
+    status: ACTIVE
+  name: team-viewers
+`, out.String())
+
+	assert.ErrorContains(t, snapshot.Write(out, "xml"), `unsupported snapshot format "xml"`)
+}
+
+func TestReadSnapshot(t *testing.T) {
+	client, _ := newSnapshotServer(t)
+
+	groups, err := client.GroupsByName(context.Background(), []string{"team-admins", "team-empty", "other"}, 10)
+	require.NoError(t, err)
+	snapshot, err := client.SnapshotGroups(context.Background(), groups)
+	require.NoError(t, err)
+
+	for _, format := range []SnapshotFormat{SnapshotJSON, SnapshotYAML, SnapshotCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			require.NoError(t, snapshot.Write(out, format))
+
+			read, err := ReadSnapshot(out, format)
+			require.NoError(t, err)
+
+			if format == SnapshotCSV {
+				assert.Empty(t, DiffSnapshots(snapshot, read).Changed)
+				assert.False(t, DiffSnapshots(snapshot, read).HasChanges())
+				return
+			}
+			assert.Equal(t, snapshot, read)
+		})
+	}
+
+	_, err = ReadSnapshot(bytes.NewBufferString("id,name\n"), SnapshotCSV)
+	assert.ErrorContains(t, err, "csv snapshot must start with the header")
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	client, server := newSnapshotServer(t)
+	ctx := context.Background()
+	search := Sw("profile.name", "team-")
+
+	previous, err := client.SnapshotSearch(ctx, search)
+	require.NoError(t, err)
+
+	unchanged, err := client.SnapshotSearch(ctx, search)
+	require.NoError(t, err)
+	assert.False(t, DiffSnapshots(previous, unchanged).HasChanges())
+
+	server.AddGroup(oktatest.Group{ID: "g5", Name: "team-new"})
+	server.AddMembers("team-new", "u3")
+	server.AddMembers("team-admins", "u1")
+	_, err = client.GroupAPI.UnassignUserFromGroup(ctx, "g1", "u3").Execute()
+	require.NoError(t, err)
+	_, err = client.GroupAPI.DeleteGroup(ctx, "g3").Execute()
+	require.NoError(t, err)
+
+	current, err := client.SnapshotSearch(ctx, search)
+	require.NoError(t, err)
+
+	assert.Equal(t, SnapshotDiff{
+		AddedGroups:   []GroupSnapshot{{ID: "g5", Name: "team-new", Members: []MemberSnapshot{{ID: "u3", Login: "bob@example.com", Status: "SUSPENDED"}}}},
+		RemovedGroups: []GroupSnapshot{{ID: "g3", Name: "team-empty", Members: []MemberSnapshot{}}},
+		Changed: []MembershipDiff{
+			{GroupID: "g2", GroupName: "team-admins", Added: []MemberSnapshot{{ID: "u1", Login: "carol@example.com", Status: "ACTIVE"}}, Removed: []MemberSnapshot{}},
+			{GroupID: "g1", GroupName: "team-viewers", Added: []MemberSnapshot{}, Removed: []MemberSnapshot{{ID: "u3", Login: "bob@example.com", Status: "SUSPENDED"}}},
+		},
+	}, DiffSnapshots(previous, current))
+}
@@ -0,0 +1,100 @@
+package oktatest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"maps"
+	"net/http"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// DefaultAudience is the audience of access tokens issued by the default authorization server.
+const DefaultAudience = "api://default"
+
+// Issuer returns the issuer of the default authorization server, whose keys are served at Issuer()/v1/keys.
+func (s *Server) Issuer() string {
+	return s.URL + "/oauth2/default"
+}
+
+// AccessToken returns an RS256 signed access token issued by the default authorization server.
+// The token has the iss, aud, iat and exp claims of a token valid for an hour, the claims
+// passed in are added to and override them, a nil value removes a claim.
+/*
+
+Example usage:
+
+token := server.AccessToken(map[string]any{
+	"sub":    "alice@example.com",
+	"scp":    []string{"openid", "profile"},
+	"groups": []string{"admins"},
+})
+req.Header.Set("Authorization", "Bearer "+token)
+*/
+func (s *Server) AccessToken(claims map[string]any) string {
+	s.mu.Lock()
+	if len(s.signingKeys) == 0 {
+		s.signingKeys = append(s.signingKeys, newSigningKey())
+	}
+	key := s.signingKeys[len(s.signingKeys)-1]
+	s.mu.Unlock()
+
+	now := time.Now()
+	all := map[string]any{
+		"iss": s.Issuer(),
+		"aud": DefaultAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	maps.Copy(all, claims)
+	maps.DeleteFunc(all, func(_ string, v any) bool { return v == nil })
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		panic(err)
+	}
+	token, err := jwt.Signed(signer).Claims(all).Serialize()
+	if err != nil {
+		panic(err)
+	}
+	return token
+}
+
+// RotateSigningKey signs subsequent access tokens with a new key. The previous keys remain published
+// so tokens issued before the rotation stay valid, as they do in Okta.
+func (s *Server) RotateSigningKey() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signingKeys = append(s.signingKeys, newSigningKey())
+}
+
+func newSigningKey() jose.JSONWebKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	jwk := jose.JSONWebKey{Key: key, Algorithm: string(jose.RS256), Use: "sig"}
+
+	// key ids are unique across servers so tokens from another server are signed with an unknown key
+	thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+	if err != nil {
+		panic(err)
+	}
+	jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+	return jwk
+}
+
+func (s *Server) keys(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}
+	for _, key := range s.signingKeys {
+		keys.Keys = append(keys.Keys, key.Public())
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
@@ -0,0 +1,257 @@
+package oktatest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// expression is a parsed Okta filter or search expression that can be evaluated against
+// the flattened attributes of a resource, e.g. "profile.name" or "status".
+type expression interface {
+	match(attrs map[string]string) bool
+}
+
+type orExpression struct{ left, right expression }
+
+func (e orExpression) match(attrs map[string]string) bool {
+	return e.left.match(attrs) || e.right.match(attrs)
+}
+
+type andExpression struct{ left, right expression }
+
+func (e andExpression) match(attrs map[string]string) bool {
+	return e.left.match(attrs) && e.right.match(attrs)
+}
+
+type notExpression struct{ expr expression }
+
+func (e notExpression) match(attrs map[string]string) bool {
+	return !e.expr.match(attrs)
+}
+
+type comparison struct {
+	attr  string
+	op    string
+	value string
+}
+
+// match compares attribute values case insensitively as the Okta API does for strings,
+// ordering comparisons are numeric when both sides are numbers and lexical otherwise which
+// is correct for the ISO 8601 timestamps Okta uses.
+func (c comparison) match(attrs map[string]string) bool {
+	actual, ok := attrs[c.attr]
+	if c.op == "pr" {
+		return ok && actual != ""
+	}
+	if !ok {
+		return false
+	}
+
+	a, v := strings.ToLower(actual), strings.ToLower(c.value)
+	switch c.op {
+	case "eq":
+		return a == v
+	case "ne":
+		return a != v
+	case "sw":
+		return strings.HasPrefix(a, v)
+	case "ew":
+		return strings.HasSuffix(a, v)
+	case "co":
+		return strings.Contains(a, v)
+	}
+
+	order := strings.Compare(a, v)
+	af, aErr := strconv.ParseFloat(a, 64)
+	vf, vErr := strconv.ParseFloat(v, 64)
+	if aErr == nil && vErr == nil {
+		order = 0
+		if af < vf {
+			order = -1
+		} else if af > vf {
+			order = 1
+		}
+	}
+
+	switch c.op {
+	case "gt":
+		return order > 0
+	case "ge":
+		return order >= 0
+	case "lt":
+		return order < 0
+	case "le":
+		return order <= 0
+	}
+	return false
+}
+
+var operators = map[string]bool{
+	"eq": true, "ne": true, "sw": true, "ew": true, "co": true, "pr": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// parseExpression parses an Okta filter or search expression such as
+// `profile.name eq "admins" or (status eq "ACTIVE" and not profile.login sw "svc")`.
+func parseExpression(input string) (expression, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].text, p.pos)
+	}
+	return expr, nil
+}
+
+type token struct {
+	text string
+	// quoted is true for string literals so they are never treated as keywords
+	quoted bool
+}
+
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(input); {
+		switch ch := input[i]; {
+		case ch == ' ' || ch == '\t':
+			i++
+		case ch == '(' || ch == ')':
+			tokens = append(tokens, token{text: string(ch)})
+			i++
+		case ch == '"':
+			value := &strings.Builder{}
+			i++
+			for ; i < len(input) && input[i] != '"'; i++ {
+				if input[i] == '\\' && i+1 < len(input) {
+					i++
+				}
+				value.WriteByte(input[i])
+			}
+			if i >= len(input) {
+				return nil, fmt.Errorf("unterminated string in %q", input)
+			}
+			tokens = append(tokens, token{text: value.String(), quoted: true})
+			i++
+		default:
+			start := i
+			for i < len(input) && !strings.ContainsRune(" \t()\"", rune(input[i])) {
+				i++
+			}
+			tokens = append(tokens, token{text: input[start:i]})
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *parser) next() (token, error) {
+	if p.pos >= len(p.tokens) {
+		return token{}, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{expr}, nil
+	}
+
+	if p.peekKeyword("(") {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peekKeyword(")") {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	attr, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if attr.quoted {
+		return nil, fmt.Errorf("expected attribute name, got string %q", attr.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	opText := strings.ToLower(op.text)
+	if op.quoted || !operators[opText] {
+		return nil, fmt.Errorf("unsupported operator %q", op.text)
+	}
+
+	if opText == "pr" {
+		return comparison{attr: attr.text, op: opText}, nil
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{attr: attr.text, op: opText, value: value.text}, nil
+}
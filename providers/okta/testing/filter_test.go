@@ -0,0 +1,67 @@
+package oktatest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpression(t *testing.T) {
+	attrs := map[string]string{
+		"id":            "00u1",
+		"status":        "ACTIVE",
+		"profile.login": "alice@example.com",
+		"profile.name":  `say "hi"`,
+		"lastUpdated":   "2024-05-01T00:00:00.000Z",
+		"count":         "10",
+	}
+
+	tests := map[string]struct {
+		expr string
+		want bool
+	}{
+		"eq":                        {`status eq "ACTIVE"`, true},
+		"eq case insensitive":       {`profile.login eq "ALICE@example.com"`, true},
+		"eq mismatch":               {`status eq "SUSPENDED"`, false},
+		"ne":                        {`status ne "SUSPENDED"`, true},
+		"sw":                        {`profile.login sw "ali"`, true},
+		"ew":                        {`profile.login ew "example.com"`, true},
+		"co":                        {`profile.login co "@exa"`, true},
+		"pr":                        {`profile.login pr`, true},
+		"pr missing":                {`profile.email pr`, false},
+		"escaped quotes":            {`profile.name eq "say \"hi\""`, true},
+		"gt timestamp":              {`lastUpdated gt "2024-01-01T00:00:00.000Z"`, true},
+		"lt timestamp":              {`lastUpdated lt "2024-01-01T00:00:00.000Z"`, false},
+		"numeric compare":           {`count gt 9`, true},
+		"or":                        {`status eq "SUSPENDED" or id eq "00u1"`, true},
+		"and":                       {`status eq "ACTIVE" and id eq "00u2"`, false},
+		"not":                       {`not status eq "SUSPENDED"`, true},
+		"and binds tighter than or": {`id eq "00u1" or status eq "X" and id eq "Y"`, true},
+		"grouping":                  {`(id eq "00u1" or status eq "X") and id eq "Y"`, false},
+		"keywords in strings":       {`profile.login ne "and" and status eq "ACTIVE"`, true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expr, err := parseExpression(test.expr)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, expr.match(attrs))
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	for _, input := range []string{
+		`status`,
+		`status eq`,
+		`status foo "ACTIVE"`,
+		`status eq "ACTIVE`,
+		`(status eq "ACTIVE"`,
+		`status eq "ACTIVE")`,
+		`"status" eq "ACTIVE"`,
+	} {
+		_, err := parseExpression(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,1465 @@
+// Package oktatest provides an in-memory fake Okta org served over HTTP for testing code that uses
+// the Okta management API, including the providers/okta Client.
+package oktatest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+)
+
+// DefaultPageSize is the number of results returned by list endpoints when no limit is requested.
+const DefaultPageSize = 200
+
+// User is an Okta user fixture.
+type User struct {
+	// ID is generated when empty
+	ID        string
+	Login     string
+	Email     string
+	FirstName string
+	LastName  string
+	// Status defaults to ACTIVE
+	Status string
+}
+
+// Group is an Okta group fixture.
+type Group struct {
+	// ID is generated when empty
+	ID          string
+	Name        string
+	Description string
+}
+
+// App is an Okta application fixture, served as an OpenID Connect app.
+type App struct {
+	// ID is generated when empty
+	ID string
+	// Name defaults to oidc_client
+	Name  string
+	Label string
+	// Status defaults to ACTIVE
+	Status string
+	// Links is the number of app links listed for each assigned user, defaults to 1.
+	// Suites such as Office 365 have a link for each of their tiles.
+	Links int
+	// Hidden apps have no app links, like apps hidden from the dashboard or used only for provisioning
+	Hidden bool
+}
+
+// GroupRule is an Okta group rule fixture that assigns members of the source groups to the target groups.
+// The rule's expression is rendered as isMemberOfAnyGroup() of the source group ids.
+type GroupRule struct {
+	// ID is generated when empty
+	ID   string
+	Name string
+	// Status defaults to ACTIVE
+	Status string
+	// Groups are the source group ids or names
+	Groups []string
+	// Assign are the target group ids or names
+	Assign []string
+}
+
+// LogEvent is an Okta System Log event fixture.
+type LogEvent struct {
+	// UUID is generated when empty
+	UUID string
+	// Published defaults to the time the event is added
+	Published      time.Time
+	EventType      string
+	DisplayMessage string
+	// Severity defaults to INFO
+	Severity string
+	// Actor is the alternateId, e.g. login, of the actor
+	Actor string
+	// Outcome defaults to SUCCESS
+	Outcome string
+}
+
+// Fixtures seeds the org with users, groups, apps and their memberships and assignments.
+type Fixtures struct {
+	Users      []User
+	Groups     []Group
+	Apps       []App
+	GroupRules []GroupRule
+	// Members maps group ids or names to user ids or logins
+	Members map[string][]string
+	// AppGroups maps app ids or labels to the assigned group ids or names
+	AppGroups map[string][]string
+	// AppUsers maps app ids or labels to the directly assigned user ids or logins
+	AppUsers map[string][]string
+	// LogEvents are added to the System Log
+	LogEvents []LogEvent
+}
+
+// Fault makes matching requests fail with Status instead of being served.
+type Fault struct {
+	// Method matches any method when empty
+	Method string
+	// PathPrefix matches any path when empty
+	PathPrefix string
+	// Status is the HTTP status code returned, 429 responses include rate limit headers
+	Status int
+	// Times is how many requests fail before the fault is removed, zero or less fails every request
+	Times int
+}
+
+func (f *Fault) matches(r *http.Request) bool {
+	return (f.Method == "" || f.Method == r.Method) && strings.HasPrefix(r.URL.Path, f.PathPrefix)
+}
+
+// Server is a fake Okta org. It issues access tokens to any client assertion and serves the
+// group, group member, group rule, user, app, app assignment and system log endpoints with Okta style
+// search, filter, sort and Link header pagination. Its default authorization server signs access tokens
+// for testing resource servers, see AccessToken.
+/*
+
+Example usage:
+
+server := oktatest.NewServer()
+defer server.Close()
+
+server.Seed(oktatest.Fixtures{
+	Users:   []oktatest.User{{Login: "alice@example.com"}},
+	Groups:  []oktatest.Group{{Name: "admins"}},
+	Members: map[string][]string{"admins": {"alice@example.com"}},
+})
+
+client, err := okta.NewClient(server.URL, "client-id", server.PrivateKey())
+*/
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	users      []*User
+	groups     []*Group
+	apps       []*App
+	rules      []*GroupRule
+	members    map[string][]string
+	appGroups  map[string][]string
+	appUsers   map[string][]string
+	logs       []*LogEvent
+	faults     []*Fault
+	requests   []string
+	nextID     int
+	requestID  int
+	privateKey string
+	// signingKeys sign access tokens issued by the default authorization server, the last key is current
+	signingKeys []jose.JSONWebKey
+}
+
+// NewServer starts a fake Okta org, callers must call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		members:   map[string][]string{},
+		appGroups: map[string][]string{},
+		appUsers:  map[string][]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth2/v1/token", s.token)
+	mux.HandleFunc("GET /oauth2/default/v1/keys", s.keys)
+	mux.HandleFunc("GET /api/v1/groups", s.listGroups)
+	mux.HandleFunc("POST /api/v1/groups", s.createGroup)
+	mux.HandleFunc("GET /api/v1/groups/rules", s.listGroupRules)
+	mux.HandleFunc("GET /api/v1/groups/{groupId}", s.getGroup)
+	mux.HandleFunc("DELETE /api/v1/groups/{groupId}", s.deleteGroup)
+	mux.HandleFunc("GET /api/v1/groups/{groupId}/users", s.listGroupUsers)
+	mux.HandleFunc("PUT /api/v1/groups/{groupId}/users/{userId}", s.assignUser)
+	mux.HandleFunc("DELETE /api/v1/groups/{groupId}/users/{userId}", s.unassignUser)
+	mux.HandleFunc("GET /api/v1/users", s.listUsers)
+	mux.HandleFunc("POST /api/v1/users", s.createUser)
+	mux.HandleFunc("GET /api/v1/users/{userId}", s.getUser)
+	mux.HandleFunc("DELETE /api/v1/users/{userId}", s.deleteUser)
+	mux.HandleFunc("POST /api/v1/users/{userId}/lifecycle/{operation}", s.userLifecycle)
+	mux.HandleFunc("GET /api/v1/users/{userId}/groups", s.listUserGroups)
+	mux.HandleFunc("GET /api/v1/users/{userId}/appLinks", s.listAppLinks)
+	mux.HandleFunc("GET /api/v1/logs", s.listLogEvents)
+	mux.HandleFunc("GET /api/v1/apps", s.listApps)
+	mux.HandleFunc("GET /api/v1/apps/{appId}/groups", s.listAppGroups)
+	mux.HandleFunc("PUT /api/v1/apps/{appId}/groups/{groupId}", s.assignAppGroup)
+	mux.HandleFunc("DELETE /api/v1/apps/{appId}/groups/{groupId}", s.unassignAppGroup)
+	mux.HandleFunc("GET /api/v1/apps/{appId}/users", s.listAppUsers)
+	mux.HandleFunc("GET /api/v1/apps/{appId}/users/{userId}", s.getAppUser)
+	mux.HandleFunc("DELETE /api/v1/apps/{appId}/users/{userId}", s.unassignAppUser)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// NewTestServer starts a fake Okta org seeded with the fixtures, which is closed when the test finishes.
+/*
+
+Example usage:
+
+server := oktatest.NewTestServer(t, oktatest.Fixtures{Groups: []oktatest.Group{{Name: "admins"}}})
+client, err := okta.NewClient(server.URL, "client-id", server.PrivateKey())
+*/
+func NewTestServer(t testing.TB, fixtures Fixtures) *Server {
+	t.Helper()
+
+	s := NewServer()
+	t.Cleanup(s.Close)
+	s.Seed(fixtures)
+	return s
+}
+
+// PrivateKey returns a PEM encoded private key that can be passed to okta.NewClient.
+// The server does not verify client assertions so any key is accepted.
+func (s *Server) PrivateKey() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.privateKey == "" {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			panic(err)
+		}
+		s.privateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	}
+	return s.privateKey
+}
+
+// Seed adds the fixtures to the org. Members may reference fixtures by id, group name or user login.
+func (s *Server) Seed(f Fixtures) {
+	for _, u := range f.Users {
+		s.AddUser(u)
+	}
+	for _, g := range f.Groups {
+		s.AddGroup(g)
+	}
+	for _, a := range f.Apps {
+		s.AddApp(a)
+	}
+	for _, r := range f.GroupRules {
+		s.AddGroupRule(r)
+	}
+	for group, users := range f.Members {
+		s.AddMembers(group, users...)
+	}
+	for app, groups := range f.AppGroups {
+		s.AssignAppGroups(app, groups...)
+	}
+	for app, users := range f.AppUsers {
+		s.AssignAppUsers(app, users...)
+	}
+	s.AddLogEvents(f.LogEvents...)
+}
+
+// AddUser adds a user to the org and returns it with its generated defaults.
+func (s *Server) AddUser(u User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.ID == "" {
+		u.ID = s.newID("00u")
+	}
+	if u.Status == "" {
+		u.Status = "ACTIVE"
+	}
+	if u.Email == "" {
+		u.Email = u.Login
+	}
+	s.users = append(s.users, &u)
+	return u
+}
+
+// AddGroup adds a group to the org and returns it with its generated defaults.
+func (s *Server) AddGroup(g Group) Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if g.ID == "" {
+		g.ID = s.newID("00g")
+	}
+	s.groups = append(s.groups, &g)
+	return g
+}
+
+// AddMembers adds users, by id or login, to a group, by id or name.
+// It panics if the group or any user does not exist since that is a broken fixture.
+func (s *Server) AddMembers(group string, users ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroup(group)
+	if g == nil {
+		panic(fmt.Sprintf("oktatest: group %q does not exist", group))
+	}
+	for _, user := range users {
+		u := s.findUser(user)
+		if u == nil {
+			panic(fmt.Sprintf("oktatest: user %q does not exist", user))
+		}
+		if !slices.Contains(s.members[g.ID], u.ID) {
+			s.members[g.ID] = append(s.members[g.ID], u.ID)
+		}
+	}
+}
+
+// User returns the user with the id or login.
+func (s *Server) User(idOrLogin string) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(idOrLogin)
+	if u == nil {
+		return User{}, false
+	}
+	return *u, true
+}
+
+// AppUsers returns the sorted ids of the users assigned directly to an app, by id or label.
+func (s *Server) AppUsers(app string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findApp(app)
+	if a == nil {
+		return nil
+	}
+	users := append([]string{}, s.appUsers[a.ID]...)
+	slices.Sort(users)
+	return users
+}
+
+// AppGroups returns the ids of the groups assigned to an app, by id or label, in priority order.
+func (s *Server) AppGroups(app string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findApp(app)
+	if a == nil {
+		return nil
+	}
+	return append([]string{}, s.appGroups[a.ID]...)
+}
+
+// AddApp adds an application to the org and returns it with its generated defaults.
+func (s *Server) AddApp(a App) App {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a.ID == "" {
+		a.ID = s.newID("0oa")
+	}
+	if a.Name == "" {
+		a.Name = "oidc_client"
+	}
+	if a.Status == "" {
+		a.Status = "ACTIVE"
+	}
+	s.apps = append(s.apps, &a)
+	return a
+}
+
+// AddGroupRule adds a group rule to the org and returns it with its generated defaults and
+// groups resolved to ids. It panics if any group does not exist.
+// Rules are not evaluated, members of the target groups must be seeded as well.
+func (s *Server) AddGroupRule(r GroupRule) GroupRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.ID == "" {
+		r.ID = s.newID("0pr")
+	}
+	if r.Status == "" {
+		r.Status = "ACTIVE"
+	}
+	r.Groups = s.mustGroupIDs(r.Groups)
+	r.Assign = s.mustGroupIDs(r.Assign)
+	s.rules = append(s.rules, &r)
+	return r
+}
+
+// AssignAppGroups assigns groups, by id or name, to an app, by id or label.
+// It panics if the app or any group does not exist.
+func (s *Server) AssignAppGroups(app string, groups ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.mustApp(app)
+	for _, id := range s.mustGroupIDs(groups) {
+		if !slices.Contains(s.appGroups[a.ID], id) {
+			s.appGroups[a.ID] = append(s.appGroups[a.ID], id)
+		}
+	}
+}
+
+// AssignAppUsers assigns users, by id or login, directly to an app, by id or label.
+// It panics if the app or any user does not exist.
+func (s *Server) AssignAppUsers(app string, users ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.mustApp(app)
+	for _, user := range users {
+		u := s.findUser(user)
+		if u == nil {
+			panic(fmt.Sprintf("oktatest: user %q does not exist", user))
+		}
+		if !slices.Contains(s.appUsers[a.ID], u.ID) {
+			s.appUsers[a.ID] = append(s.appUsers[a.ID], u.ID)
+		}
+	}
+}
+
+func (s *Server) mustApp(idOrLabel string) *App {
+	a := s.findApp(idOrLabel)
+	if a == nil {
+		panic(fmt.Sprintf("oktatest: app %q does not exist", idOrLabel))
+	}
+	return a
+}
+
+func (s *Server) mustGroupIDs(groups []string) []string {
+	ids := []string{}
+	for _, group := range groups {
+		g := s.findGroup(group)
+		if g == nil {
+			panic(fmt.Sprintf("oktatest: group %q does not exist", group))
+		}
+		ids = append(ids, g.ID)
+	}
+	return ids
+}
+
+// AddLogEvents appends events to the System Log, which is kept in published order.
+func (s *Server) AddLogEvents(events ...LogEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range events {
+		if e.UUID == "" {
+			e.UUID = s.newID("log")
+		}
+		if e.Published.IsZero() {
+			e.Published = time.Now()
+		}
+		if e.Severity == "" {
+			e.Severity = "INFO"
+		}
+		if e.Outcome == "" {
+			e.Outcome = "SUCCESS"
+		}
+		s.logs = append(s.logs, &e)
+	}
+	slices.SortStableFunc(s.logs, func(a, b *LogEvent) int {
+		return a.Published.Compare(b.Published)
+	})
+}
+
+// Members returns the sorted user ids that belong to a group, by id or name.
+func (s *Server) Members(group string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroup(group)
+	if g == nil {
+		return nil
+	}
+	members := append([]string{}, s.members[g.ID]...)
+	slices.Sort(members)
+	return members
+}
+
+// InjectFault makes matching requests fail until the fault has been triggered f.Times times.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns the "METHOD /path" of every API request received, excluding token requests.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s%017d", prefix, s.nextID)
+}
+
+func (s *Server) findUser(idOrLogin string) *User {
+	for _, u := range s.users {
+		if u.ID == idOrLogin || strings.EqualFold(u.Login, idOrLogin) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) findGroup(idOrName string) *Group {
+	for _, g := range s.groups {
+		if g.ID == idOrName || g.Name == idOrName {
+			return g
+		}
+	}
+	return nil
+}
+
+func (s *Server) findApp(idOrLabel string) *App {
+	for _, a := range s.apps {
+		if a.ID == idOrLabel || a.Label == idOrLabel {
+			return a
+		}
+	}
+	return nil
+}
+
+// middleware records requests, tags responses with a request id and applies injected faults.
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requestID++
+		requestID := fmt.Sprintf("oktatest-%d", s.requestID)
+		if r.URL.Path != "/oauth2/v1/token" {
+			s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		}
+
+		var fault *Fault
+		for i, f := range s.faults {
+			if f.matches(r) {
+				fault = f
+				if f.Times > 0 {
+					if f.Times--; f.Times == 0 {
+						s.faults = slices.Delete(s.faults, i, i+1)
+					}
+				}
+				break
+			}
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("X-Okta-Request-Id", requestID)
+
+		if fault != nil {
+			writeFault(w, fault.Status, requestID)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeFault(w http.ResponseWriter, status int, requestID string) {
+	switch status {
+	case http.StatusTooManyRequests:
+		w.Header().Set("X-Rate-Limit-Limit", "600")
+		w.Header().Set("X-Rate-Limit-Remaining", "0")
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+		writeError(w, status, "E0000047", "API call exceeded rate limit due to too many requests.", requestID)
+	case http.StatusUnauthorized:
+		writeError(w, status, "E0000011", "Invalid token provided", requestID)
+	case http.StatusForbidden:
+		writeError(w, status, "E0000006", "You do not have permission to perform the requested action", requestID)
+	case http.StatusNotFound:
+		writeError(w, status, "E0000007", "Not found: Resource not found", requestID)
+	default:
+		writeError(w, status, "E0000009", "Internal Server Error", requestID)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, code, summary, requestID string) {
+	writeJSON(w, status, map[string]any{
+		"errorCode":    code,
+		"errorSummary": summary,
+		"errorLink":    code,
+		"errorId":      requestID,
+		"errorCauses":  []any{},
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, r *http.Request, resource string) {
+	writeError(w, http.StatusNotFound, "E0000007", fmt.Sprintf("Not found: Resource not found: %s (%s)", r.PathValue(strings.ToLower(resource)+"Id"), resource), w.Header().Get("X-Okta-Request-Id"))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"token_type":   "Bearer",
+		"access_token": "oktatest-access-token",
+		"expires_in":   3600,
+		"scope":        r.FormValue("scope"),
+	})
+}
+
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	matches, err := matcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "E0000031", fmt.Sprintf("Invalid search criteria: %s", err), w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	results := []*Group{}
+	for _, g := range s.groups {
+		if q := query.Get("q"); q != "" && !strings.HasPrefix(strings.ToLower(g.Name), strings.ToLower(q)) {
+			continue
+		}
+		if matches(groupAttributes(g)) {
+			results = append(results, g)
+		}
+	}
+	sortResults(results, query, groupAttributes)
+
+	stats := strings.Contains(query.Get("expand"), "stats")
+	writePage(w, r, results, func(g *Group) string { return g.ID }, func(g *Group) any {
+		return s.groupJSON(g, stats)
+	})
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Profile struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		} `json:"profile"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Profile.Name == "" {
+		writeError(w, http.StatusBadRequest, "E0000001", "Api validation failed: name", w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	g := s.AddGroup(Group{Name: body.Profile.Name, Description: body.Profile.Description})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.groupJSON(&g, false))
+}
+
+func (s *Server) getGroup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroupByID(r.PathValue("groupId"))
+	if g == nil {
+		writeNotFound(w, r, "Group")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.groupJSON(g, strings.Contains(r.URL.Query().Get("expand"), "stats")))
+}
+
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroupByID(r.PathValue("groupId"))
+	if g == nil {
+		writeNotFound(w, r, "Group")
+		return
+	}
+
+	s.groups = slices.DeleteFunc(s.groups, func(group *Group) bool { return group == g })
+	delete(s.members, g.ID)
+	for id := range s.appGroups {
+		s.appGroups[id] = slices.DeleteFunc(s.appGroups[id], func(groupID string) bool { return groupID == g.ID })
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listGroupUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroupByID(r.PathValue("groupId"))
+	if g == nil {
+		writeNotFound(w, r, "Group")
+		return
+	}
+
+	query := r.URL.Query()
+	matches, err := matcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "E0000031", fmt.Sprintf("Invalid search criteria: %s", err), w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	users := []*User{}
+	for _, id := range s.members[g.ID] {
+		if u := s.findUser(id); matches(userAttributes(u)) {
+			users = append(users, u)
+		}
+	}
+	sortResults(users, query, userAttributes)
+
+	writePage(w, r, users, func(u *User) string { return u.ID }, userJSON)
+}
+
+func (s *Server) assignUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroupByID(r.PathValue("groupId"))
+	if g == nil {
+		writeNotFound(w, r, "Group")
+		return
+	}
+	u := s.findUserByID(r.PathValue("userId"))
+	if u == nil {
+		writeNotFound(w, r, "User")
+		return
+	}
+
+	if !slices.Contains(s.members[g.ID], u.ID) {
+		s.members[g.ID] = append(s.members[g.ID], u.ID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) unassignUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroupByID(r.PathValue("groupId"))
+	if g == nil {
+		writeNotFound(w, r, "Group")
+		return
+	}
+
+	s.members[g.ID] = slices.DeleteFunc(s.members[g.ID], func(id string) bool {
+		return id == r.PathValue("userId")
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	matches, err := matcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "E0000031", fmt.Sprintf("Invalid search criteria: %s", err), w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	results := []*User{}
+	for _, u := range s.users {
+		if q := strings.ToLower(query.Get("q")); q != "" &&
+			!strings.HasPrefix(strings.ToLower(u.FirstName), q) &&
+			!strings.HasPrefix(strings.ToLower(u.LastName), q) &&
+			!strings.HasPrefix(strings.ToLower(u.Email), q) {
+			continue
+		}
+		if matches(userAttributes(u)) {
+			results = append(results, u)
+		}
+	}
+	sortResults(results, query, userAttributes)
+
+	writePage(w, r, results, func(u *User) string { return u.ID }, userJSON)
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUser(r.PathValue("userId"))
+	if u == nil {
+		writeNotFound(w, r, "User")
+		return
+	}
+	writeJSON(w, http.StatusOK, userJSON(u))
+}
+
+func (s *Server) listGroupRules(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writePage(w, r, s.rules, func(rule *GroupRule) string { return rule.ID }, groupRuleJSON)
+}
+
+// listLogEvents serves the System Log in ascending order. Like Okta's polling mode the response
+// always has a next link, which repeats the cursor when there are no new events.
+func (s *Server) listLogEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	matches, err := matcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "E0000031", fmt.Sprintf("Invalid search criteria: %s", err), w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	events := s.logs
+	if after := query.Get("after"); after != "" {
+		i := slices.IndexFunc(events, func(e *LogEvent) bool { return e.UUID == after })
+		if i < 0 {
+			writeError(w, http.StatusBadRequest, "E0000001", "Api validation failed: after", w.Header().Get("X-Okta-Request-Id"))
+			return
+		}
+		events = events[i+1:]
+	} else if since := query.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "E0000001", "Api validation failed: since", w.Header().Get("X-Okta-Request-Id"))
+			return
+		}
+		i, _ := slices.BinarySearchFunc(events, t, func(e *LogEvent, t time.Time) int { return e.Published.Compare(t) })
+		events = events[i:]
+	}
+
+	limit := 100
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	page := []any{}
+	cursor := query.Get("after")
+	for _, e := range events {
+		if len(page) == limit {
+			break
+		}
+		// filtered out events still advance the cursor
+		cursor = e.UUID
+		if matches(logEventAttributes(e)) {
+			page = append(page, logEventJSON(e))
+		}
+	}
+
+	link := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="self"`, link.String()))
+	if cursor != "" {
+		query.Del("since")
+		query.Set("after", cursor)
+	}
+	link.RawQuery = query.Encode()
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, link.String()))
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) listApps(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	matches, err := matcher(query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "E0000031", fmt.Sprintf("Invalid search criteria: %s", err), w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	results := []*App{}
+	for _, a := range s.apps {
+		if q := strings.ToLower(query.Get("q")); q != "" && !strings.HasPrefix(strings.ToLower(a.Label), q) && !strings.HasPrefix(strings.ToLower(a.Name), q) {
+			continue
+		}
+		// user.id matches the users assigned to the app directly or through a group
+		attrs := map[string]string{"id": a.ID, "name": a.Name, "label": a.Label, "status": a.Status}
+		matched := matches(attrs)
+		for _, u := range s.users {
+			if matched {
+				break
+			}
+			if s.appUserScope(a.ID, u.ID) != "" {
+				attrs["user.id"] = u.ID
+				matched = matches(attrs)
+			}
+		}
+		if !matched {
+			continue
+		}
+		results = append(results, a)
+	}
+
+	writePage(w, r, results, func(a *App) string { return a.ID }, appJSON)
+}
+
+// listAppGroups lists the app's group assignments in priority order, the assigned group is
+// embedded with expand=group.
+func (s *Server) listAppGroups(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAppByID(r.PathValue("appId"))
+	if a == nil {
+		writeNotFound(w, r, "App")
+		return
+	}
+
+	groups := []*Group{}
+	for _, id := range s.appGroups[a.ID] {
+		groups = append(groups, s.findGroupByID(id))
+	}
+
+	expand := strings.Contains(r.URL.Query().Get("expand"), "group")
+	writePage(w, r, groups, func(g *Group) string { return g.ID }, func(g *Group) any {
+		out := s.appGroupJSON(a, g)
+		if expand {
+			out["_embedded"] = map[string]any{"group": s.groupJSON(g, false)}
+		}
+		return out
+	})
+}
+
+// assignAppGroup assigns the group to the app at the requested priority, moving it when it is already
+// assigned. Priorities are positions in the app's assignment list, without one the group is assigned last.
+func (s *Server) assignAppGroup(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Priority *int `json:"priority"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "E0000003", "The request body was not well-formed.", w.Header().Get("X-Okta-Request-Id"))
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAppByID(r.PathValue("appId"))
+	if a == nil {
+		writeNotFound(w, r, "App")
+		return
+	}
+	g := s.findGroupByID(r.PathValue("groupId"))
+	if g == nil {
+		writeNotFound(w, r, "Group")
+		return
+	}
+
+	assigned := slices.DeleteFunc(s.appGroups[a.ID], func(id string) bool { return id == g.ID })
+	position := len(assigned)
+	if body.Priority != nil {
+		position = min(max(*body.Priority, 0), len(assigned))
+	}
+	s.appGroups[a.ID] = slices.Insert(assigned, position, g.ID)
+
+	writeJSON(w, http.StatusOK, s.appGroupJSON(a, g))
+}
+
+func (s *Server) unassignAppGroup(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAppByID(r.PathValue("appId"))
+	if a == nil {
+		writeNotFound(w, r, "App")
+		return
+	}
+	groupID := r.PathValue("groupId")
+	if !slices.Contains(s.appGroups[a.ID], groupID) {
+		writeNotFound(w, r, "Group")
+		return
+	}
+
+	s.appGroups[a.ID] = slices.DeleteFunc(s.appGroups[a.ID], func(id string) bool { return id == groupID })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listAppUsers lists the users assigned directly, with scope USER, followed by those
+// assigned through a group, with scope GROUP. Each user is embedded with expand=user.
+func (s *Server) listAppUsers(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAppByID(r.PathValue("appId"))
+	if a == nil {
+		writeNotFound(w, r, "App")
+		return
+	}
+
+	users := []*User{}
+	seen := map[string]bool{}
+	for _, id := range s.appUsers[a.ID] {
+		seen[id] = true
+		users = append(users, s.findUserByID(id))
+	}
+	for _, groupID := range s.appGroups[a.ID] {
+		for _, id := range s.members[groupID] {
+			if !seen[id] {
+				seen[id] = true
+				users = append(users, s.findUserByID(id))
+			}
+		}
+	}
+
+	expand := strings.Contains(r.URL.Query().Get("expand"), "user")
+	writePage(w, r, users, func(u *User) string { return u.ID }, func(u *User) any {
+		out := appUserJSON(u, s.appUserScope(a.ID, u.ID))
+		if expand {
+			out["_embedded"] = map[string]any{"user": userJSON(u)}
+		}
+		return out
+	})
+}
+
+func (s *Server) getAppUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAppByID(r.PathValue("appId"))
+	if a == nil {
+		writeNotFound(w, r, "App")
+		return
+	}
+	u := s.findUserByID(r.PathValue("userId"))
+	scope := ""
+	if u != nil {
+		scope = s.appUserScope(a.ID, u.ID)
+	}
+	if scope == "" {
+		writeNotFound(w, r, "User")
+		return
+	}
+	writeJSON(w, http.StatusOK, appUserJSON(u, scope))
+}
+
+// unassignAppUser removes a direct assignment, users assigned through a group cannot be unassigned.
+func (s *Server) unassignAppUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.findAppByID(r.PathValue("appId"))
+	if a == nil {
+		writeNotFound(w, r, "App")
+		return
+	}
+
+	userID := r.PathValue("userId")
+	switch s.appUserScope(a.ID, userID) {
+	case "":
+		writeNotFound(w, r, "User")
+	case "GROUP":
+		writeError(w, http.StatusForbidden, "E0000006", "You do not have permission to perform the requested action", w.Header().Get("X-Okta-Request-Id"))
+	default:
+		s.appUsers[a.ID] = slices.DeleteFunc(s.appUsers[a.ID], func(id string) bool { return id == userID })
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// appUserScope returns USER when the user is assigned to the app directly, GROUP when they are
+// assigned through a group and an empty string when they are not assigned.
+func (s *Server) appUserScope(appID, userID string) string {
+	if slices.Contains(s.appUsers[appID], userID) {
+		return "USER"
+	}
+	for _, groupID := range s.appGroups[appID] {
+		if slices.Contains(s.members[groupID], userID) {
+			return "GROUP"
+		}
+	}
+	return ""
+}
+
+func (s *Server) findAppByID(id string) *App {
+	for _, a := range s.apps {
+		if a.ID == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Profile struct {
+			Login     string `json:"login"`
+			Email     string `json:"email"`
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"profile"`
+		GroupIDs []string `json:"groupIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Profile.Login == "" {
+		writeError(w, http.StatusBadRequest, "E0000001", "Api validation failed: login", w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	if _, exists := s.User(body.Profile.Login); exists {
+		writeError(w, http.StatusBadRequest, "E0000001", "Api validation failed: login: An object with this field already exists in the current organization", w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	status := "STAGED"
+	if r.URL.Query().Get("activate") != "false" {
+		status = "ACTIVE"
+	}
+
+	u := s.AddUser(User{
+		Login:     body.Profile.Login,
+		Email:     body.Profile.Email,
+		FirstName: body.Profile.FirstName,
+		LastName:  body.Profile.LastName,
+		Status:    status,
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, groupID := range body.GroupIDs {
+		if s.findGroupByID(groupID) != nil {
+			s.members[groupID] = append(s.members[groupID], u.ID)
+		}
+	}
+	writeJSON(w, http.StatusOK, userJSON(&u))
+}
+
+// userLifecycle applies a lifecycle operation, returning an error like Okta when the operation
+// is not allowed from the user's status.
+func (s *Server) userLifecycle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUserByID(r.PathValue("userId"))
+	if u == nil {
+		writeNotFound(w, r, "User")
+		return
+	}
+
+	transitions := map[string]struct {
+		from []string
+		to   string
+	}{
+		"activate":   {[]string{"STAGED", "DEPROVISIONED"}, "ACTIVE"},
+		"reactivate": {[]string{"PROVISIONED"}, "ACTIVE"},
+		"deactivate": {[]string{"STAGED", "PROVISIONED", "ACTIVE", "RECOVERY", "PASSWORD_EXPIRED", "LOCKED_OUT", "SUSPENDED"}, "DEPROVISIONED"},
+		"suspend":    {[]string{"ACTIVE"}, "SUSPENDED"},
+		"unsuspend":  {[]string{"SUSPENDED"}, "ACTIVE"},
+	}
+
+	transition, ok := transitions[r.PathValue("operation")]
+	if !ok {
+		writeNotFound(w, r, "Operation")
+		return
+	}
+	if !slices.Contains(transition.from, u.Status) {
+		writeError(w, http.StatusForbidden, "E0000038", "This operation is not allowed in the user's current status.", w.Header().Get("X-Okta-Request-Id"))
+		return
+	}
+
+	u.Status = transition.to
+	writeJSON(w, http.StatusOK, map[string]any{})
+}
+
+// deleteUser deactivates a user that is not DEPROVISIONED and deletes one that is, as Okta does.
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUserByID(r.PathValue("userId"))
+	if u == nil {
+		writeNotFound(w, r, "User")
+		return
+	}
+
+	if u.Status != "DEPROVISIONED" {
+		u.Status = "DEPROVISIONED"
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.users = slices.DeleteFunc(s.users, func(user *User) bool { return user == u })
+	isUser := func(id string) bool { return id == u.ID }
+	for id := range s.members {
+		s.members[id] = slices.DeleteFunc(s.members[id], isUser)
+	}
+	for id := range s.appUsers {
+		s.appUsers[id] = slices.DeleteFunc(s.appUsers[id], isUser)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listUserGroups(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUserByID(r.PathValue("userId"))
+	if u == nil {
+		writeNotFound(w, r, "User")
+		return
+	}
+
+	groups := []*Group{}
+	for _, g := range s.groups {
+		if slices.Contains(s.members[g.ID], u.ID) {
+			groups = append(groups, g)
+		}
+	}
+	writePage(w, r, groups, func(g *Group) string { return g.ID }, func(g *Group) any {
+		return s.groupJSON(g, false)
+	})
+}
+
+// listAppLinks lists the apps a user is assigned to directly or through a group.
+func (s *Server) listAppLinks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := s.findUserByID(r.PathValue("userId"))
+	if u == nil {
+		writeNotFound(w, r, "User")
+		return
+	}
+
+	links := []any{}
+	for _, a := range s.apps {
+		if a.Hidden || s.appUserScope(a.ID, u.ID) == "" {
+			continue
+		}
+		for i := range max(a.Links, 1) {
+			links = append(links, map[string]any{
+				"id":            fmt.Sprintf("%s%s%d", a.ID, u.ID, i),
+				"appInstanceId": a.ID,
+				"appName":       a.Name,
+				"label":         a.Label,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+func (s *Server) findUserByID(id string) *User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *Server) findGroupByID(id string) *Group {
+	for _, g := range s.groups {
+		if g.ID == id {
+			return g
+		}
+	}
+	return nil
+}
+
+// matcher combines the search and filter query parameters into a single predicate.
+func matcher(query url.Values) (func(map[string]string) bool, error) {
+	exprs := []expression{}
+	for _, param := range []string{"search", "filter"} {
+		if value := query.Get(param); value != "" {
+			expr, err := parseExpression(value)
+			if err != nil {
+				return nil, err
+			}
+			exprs = append(exprs, expr)
+		}
+	}
+
+	return func(attrs map[string]string) bool {
+		for _, expr := range exprs {
+			if !expr.match(attrs) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// sortResults sorts items by the attribute in the sortBy query parameter, in the sortOrder
+// query parameter's direction, ascending by default.
+func sortResults[T any](items []T, query url.Values, attributes func(T) map[string]string) {
+	sortBy := query.Get("sortBy")
+	if sortBy == "" {
+		return
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		n := strings.Compare(attributes(a)[sortBy], attributes(b)[sortBy])
+		if query.Get("sortOrder") == "desc" {
+			return -n
+		}
+		return n
+	})
+}
+
+// writePage writes the page of items following the after cursor and a Link header for the next page.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T, id func(T) string, render func(T) any) {
+	query := r.URL.Query()
+
+	limit := DefaultPageSize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	start := 0
+	if after := query.Get("after"); after != "" {
+		start = len(items)
+		for i, item := range items {
+			if id(item) == after {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := min(start+limit, len(items))
+
+	page := []any{}
+	for _, item := range items[start:end] {
+		page = append(page, render(item))
+	}
+
+	link := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+	w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="self"`, link.String()))
+	if end < len(items) {
+		query.Set("after", id(items[end-1]))
+		query.Set("limit", strconv.Itoa(limit))
+		link.RawQuery = query.Encode()
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, link.String()))
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func userAttributes(u *User) map[string]string {
+	return map[string]string{
+		"id":                u.ID,
+		"status":            u.Status,
+		"profile.login":     u.Login,
+		"profile.email":     u.Email,
+		"profile.firstName": u.FirstName,
+		"profile.lastName":  u.LastName,
+	}
+}
+
+func groupAttributes(g *Group) map[string]string {
+	return map[string]string{
+		"id":                  g.ID,
+		"type":                "OKTA_GROUP",
+		"profile.name":        g.Name,
+		"profile.description": g.Description,
+	}
+}
+
+func userJSON(u *User) any {
+	return map[string]any{
+		"id":     u.ID,
+		"status": u.Status,
+		"profile": map[string]any{
+			"login":     u.Login,
+			"email":     u.Email,
+			"firstName": u.FirstName,
+			"lastName":  u.LastName,
+		},
+	}
+}
+
+func appUserJSON(u *User, scope string) map[string]any {
+	return map[string]any{
+		"id":          u.ID,
+		"scope":       scope,
+		"status":      "PROVISIONED",
+		"credentials": map[string]any{"userName": u.Login},
+	}
+}
+
+func (s *Server) groupJSON(g *Group, stats bool) any {
+	out := map[string]any{
+		"id":   g.ID,
+		"type": "OKTA_GROUP",
+		"profile": map[string]any{
+			"name":        g.Name,
+			"description": g.Description,
+		},
+	}
+	if stats {
+		out["_embedded"] = map[string]any{
+			"stats": map[string]any{"usersCount": len(s.members[g.ID])},
+		}
+	}
+	return out
+}
+
+func appJSON(a *App) any {
+	return map[string]any{
+		"id":         a.ID,
+		"name":       a.Name,
+		"label":      a.Label,
+		"status":     a.Status,
+		"signOnMode": "OPENID_CONNECT",
+		"credentials": map[string]any{
+			"oauthClient": map[string]any{"client_id": a.ID, "token_endpoint_auth_method": "client_secret_basic"},
+		},
+		"settings": map[string]any{
+			"oauthClient": map[string]any{"redirect_uris": []string{}, "response_types": []string{"code"}, "grant_types": []string{"authorization_code"}, "application_type": "web"},
+		},
+	}
+}
+
+func (s *Server) appGroupJSON(a *App, g *Group) map[string]any {
+	return map[string]any{"id": g.ID, "priority": slices.Index(s.appGroups[a.ID], g.ID)}
+}
+
+func groupRuleJSON(r *GroupRule) any {
+	quoted := []string{}
+	for _, id := range r.Groups {
+		quoted = append(quoted, strconv.Quote(id))
+	}
+
+	return map[string]any{
+		"id":     r.ID,
+		"name":   r.Name,
+		"status": r.Status,
+		"type":   "group_rule",
+		"conditions": map[string]any{
+			"expression": map[string]any{
+				"type":  "urn:okta:expression:1.0",
+				"value": fmt.Sprintf("isMemberOfAnyGroup(%s)", strings.Join(quoted, ",")),
+			},
+		},
+		"actions": map[string]any{
+			"assignUserToGroups": map[string]any{"groupIds": r.Assign},
+		},
+	}
+}
+
+func logEventAttributes(e *LogEvent) map[string]string {
+	return map[string]string{
+		"uuid":              e.UUID,
+		"eventType":         e.EventType,
+		"severity":          e.Severity,
+		"displayMessage":    e.DisplayMessage,
+		"actor.alternateId": e.Actor,
+		"outcome.result":    e.Outcome,
+	}
+}
+
+func logEventJSON(e *LogEvent) any {
+	return map[string]any{
+		"uuid":           e.UUID,
+		"published":      e.Published.UTC().Format(time.RFC3339Nano),
+		"eventType":      e.EventType,
+		"displayMessage": e.DisplayMessage,
+		"severity":       e.Severity,
+		"version":        "0",
+		"actor":          map[string]any{"alternateId": e.Actor, "type": "User"},
+		"outcome":        map[string]any{"result": e.Outcome},
+	}
+}
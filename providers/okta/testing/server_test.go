@@ -0,0 +1,116 @@
+package oktatest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kanopy-platform/go-library/providers/okta"
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, server *oktatest.Server, opts ...okta.ClientOpt) *okta.Client {
+	client, err := okta.NewClientWithOptions(server.URL, "client-id", server.PrivateKey(), opts...)
+	require.NoError(t, err)
+	return client
+}
+
+func seededServer(t *testing.T) *oktatest.Server {
+	return oktatest.NewTestServer(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com", FirstName: "Alice"},
+			{ID: "u2", Login: "bob@example.com", FirstName: "Bob"},
+			{ID: "u3", Login: "carol@example.com", FirstName: "Carol", Status: "SUSPENDED"},
+		},
+		Groups: []oktatest.Group{
+			{ID: "g1", Name: "admins"},
+			{ID: "g2", Name: "developers"},
+			{ID: "g3", Name: `quoted "name"`},
+		},
+		Members: map[string][]string{
+			"admins":     {"alice@example.com"},
+			"developers": {"u1", "u2", "u3"},
+		},
+	})
+}
+
+func TestGroupsAndMembers(t *testing.T) {
+	server := seededServer(t)
+	client := newClient(t, server)
+	ctx := context.Background()
+
+	group, err := client.GroupByName(ctx, "developers")
+	require.NoError(t, err)
+	assert.Equal(t, "g2", group.GetId())
+
+	groups, err := client.GroupsByName(ctx, []string{"admins", "developers", "missing"}, 1)
+	var notFound *okta.GroupsNotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, []string{"missing"}, notFound.Names)
+	require.Len(t, groups, 2)
+	assert.EqualValues(t, 3, groups[1].Embedded["stats"]["usersCount"])
+
+	// a page size of 1 forces the client to follow Link headers
+	users, err := client.ListGroupUsers(ctx, "g2", okta.WithLimit(1))
+	require.NoError(t, err)
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.GetId())
+	}
+	assert.Equal(t, []string{"u1", "u2", "u3"}, ids)
+	assert.Len(t, server.Requests(), 1+1+2+3)
+}
+
+func TestUserSearch(t *testing.T) {
+	server := seededServer(t)
+	client := newClient(t, server)
+	ctx := context.Background()
+
+	users, _, err := client.UserAPI.ListUsers(ctx).Search(`status eq "ACTIVE" and profile.login sw "b"`).Execute()
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "u2", users[0].GetId())
+
+	user, _, err := client.UserAPI.GetUser(ctx, "CAROL@example.com").Execute()
+	require.NoError(t, err)
+	assert.Equal(t, "u3", user.GetId())
+
+	_, resp, err := client.UserAPI.GetUser(ctx, "nobody").Execute()
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestMembershipChanges(t *testing.T) {
+	server := seededServer(t)
+	client := newClient(t, server)
+	ctx := context.Background()
+
+	_, err := client.GroupAPI.AssignUserToGroup(ctx, "g1", "u2").Execute()
+	require.NoError(t, err)
+	_, err = client.GroupAPI.UnassignUserFromGroup(ctx, "g1", "u1").Execute()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"u2"}, server.Members("admins"))
+}
+
+func TestFaults(t *testing.T) {
+	server := seededServer(t)
+	ctx := context.Background()
+
+	t.Run("rate limited requests are retried by the client", func(t *testing.T) {
+		server.InjectFault(oktatest.Fault{PathPrefix: "/api/v1/groups/g1/users", Status: http.StatusTooManyRequests, Times: 2})
+
+		users, err := newClient(t, server).ListGroupUsers(ctx, "g1")
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+	})
+
+	t.Run("persistent server errors are returned", func(t *testing.T) {
+		server.InjectFault(oktatest.Fault{Method: http.MethodGet, PathPrefix: "/api/v1/groups", Status: http.StatusInternalServerError})
+
+		_, err := newClient(t, server, okta.WithMaxRetries(1)).GroupByName(ctx, "admins")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,291 @@
+package okta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/okta/okta-sdk-golang/v5/okta"
+)
+
+// InvalidTransitionError is returned when a lifecycle operation is not allowed from the user's status,
+// e.g. activating a SUSPENDED user, which must be unsuspended instead.
+type InvalidTransitionError struct {
+	UserID    string
+	Status    string
+	Operation string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("cannot %s okta user %q with status %s", e.Operation, e.UserID, e.Status)
+}
+
+// user statuses in which the user can sign in, see https://developer.okta.com/docs/api/openapi/okta-management/management/tag/User/#user-status
+var activeStatuses = []string{"ACTIVE", "PROVISIONED", "RECOVERY", "PASSWORD_EXPIRED", "LOCKED_OUT"}
+
+// User returns the user with the id or login, or ErrUserNotFound.
+func (c *Client) User(ctx context.Context, idOrLogin string) (*okta.UserGetSingleton, error) {
+	ctx = withRateLimitRecorder(ctx)
+	user, resp, err := c.UserAPI.GetUser(ctx, idOrLogin).Execute()
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w %q", ErrUserNotFound, idOrLogin)
+	}
+	if err := apiError(ctx, resp, err); err != nil {
+		return nil, fmt.Errorf("failed to get okta user: %w", err)
+	}
+	return user, nil
+}
+
+// CreateUser creates the user, activating it when activate is set. If a user with the same login
+// already exists it is returned instead, and activated if it is STAGED and activate is set.
+func (c *Client) CreateUser(ctx context.Context, user okta.CreateUserRequest, activate bool) (*okta.User, error) {
+	ctx = withRateLimitRecorder(ctx)
+	login := user.Profile.GetLogin()
+
+	// users are looked up by login rather than searched for, since search results are eventually consistent
+	found, err := c.User(ctx, login)
+	if errors.Is(err, ErrUserNotFound) {
+		created, resp, err := c.UserAPI.CreateUser(ctx).Body(user).Activate(activate).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			return nil, fmt.Errorf("failed to create okta user %q: %w", login, err)
+		}
+		return created, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	existing := userFromSingleton(found)
+	if activate && existing.GetStatus() == "STAGED" {
+		if err := c.ActivateUser(ctx, existing.GetId(), false); err != nil {
+			return nil, err
+		}
+		existing.SetStatus("ACTIVE")
+	}
+
+	return existing, nil
+}
+
+// userFromSingleton converts a user returned by GetUser to the okta.User returned by the other user APIs.
+func userFromSingleton(user *okta.UserGetSingleton) *okta.User {
+	return &okta.User{
+		Activated:             user.Activated,
+		Created:               user.Created,
+		Credentials:           user.Credentials,
+		Id:                    user.Id,
+		LastLogin:             user.LastLogin,
+		LastUpdated:           user.LastUpdated,
+		PasswordChanged:       user.PasswordChanged,
+		Profile:               user.Profile,
+		RealmId:               user.RealmId,
+		Status:                user.Status,
+		StatusChanged:         user.StatusChanged,
+		TransitioningToStatus: user.TransitioningToStatus,
+		Type:                  user.Type,
+		Links:                 user.Links,
+		AdditionalProperties:  user.AdditionalProperties,
+	}
+}
+
+// ActivateUser activates a STAGED or DEPROVISIONED user, sending the activation email when sendEmail is set.
+// Activating a user that is already active is a no-op, a SUSPENDED user must be unsuspended instead.
+func (c *Client) ActivateUser(ctx context.Context, idOrLogin string, sendEmail bool) error {
+	return c.transitionUser(ctx, idOrLogin, "activate", activeStatuses, []string{"STAGED", "DEPROVISIONED"}, func(ctx context.Context, id string) (*okta.APIResponse, error) {
+		_, resp, err := c.UserAPI.ActivateUser(ctx, id).SendEmail(sendEmail).Execute()
+		return resp, err
+	})
+}
+
+// SuspendUser suspends an ACTIVE user, suspending a SUSPENDED user is a no-op.
+func (c *Client) SuspendUser(ctx context.Context, idOrLogin string) error {
+	return c.transitionUser(ctx, idOrLogin, "suspend", []string{"SUSPENDED"}, []string{"ACTIVE"}, func(ctx context.Context, id string) (*okta.APIResponse, error) {
+		return c.UserAPI.SuspendUser(ctx, id).Execute()
+	})
+}
+
+// UnsuspendUser returns a SUSPENDED user to ACTIVE, unsuspending an ACTIVE user is a no-op.
+func (c *Client) UnsuspendUser(ctx context.Context, idOrLogin string) error {
+	return c.transitionUser(ctx, idOrLogin, "unsuspend", []string{"ACTIVE"}, []string{"SUSPENDED"}, func(ctx context.Context, id string) (*okta.APIResponse, error) {
+		return c.UserAPI.UnsuspendUser(ctx, id).Execute()
+	})
+}
+
+// DeactivateUser deactivates the user, deactivating a DEPROVISIONED user is a no-op.
+func (c *Client) DeactivateUser(ctx context.Context, idOrLogin string) error {
+	return c.transitionUser(ctx, idOrLogin, "deactivate", []string{"DEPROVISIONED"}, nil, func(ctx context.Context, id string) (*okta.APIResponse, error) {
+		return c.UserAPI.DeactivateUser(ctx, id).Execute()
+	})
+}
+
+// DeleteUser permanently deletes the user, deactivating it first if needed.
+// Deleting a user that does not exist is a no-op.
+func (c *Client) DeleteUser(ctx context.Context, idOrLogin string) error {
+	ctx = withRateLimitRecorder(ctx)
+	user, err := c.User(ctx, idOrLogin)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.GetStatus() != "DEPROVISIONED" {
+		if err := c.DeactivateUser(ctx, user.GetId()); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.UserAPI.DeleteUser(ctx, user.GetId()).Execute()
+	if err := apiError(ctx, resp, err); err != nil {
+		return fmt.Errorf("failed to delete okta user %q: %w", idOrLogin, err)
+	}
+	return nil
+}
+
+// transitionUser calls apply when the user's status is in from, does nothing when it is already in done,
+// and otherwise returns an InvalidTransitionError. A nil from allows every status not in done.
+func (c *Client) transitionUser(ctx context.Context, idOrLogin, operation string, done, from []string, apply func(ctx context.Context, id string) (*okta.APIResponse, error)) error {
+	ctx = withRateLimitRecorder(ctx)
+	user, err := c.User(ctx, idOrLogin)
+	if err != nil {
+		return err
+	}
+
+	status := user.GetStatus()
+	if slices.Contains(done, status) {
+		return nil
+	}
+	if from != nil && !slices.Contains(from, status) {
+		return &InvalidTransitionError{UserID: user.GetId(), Status: status, Operation: operation}
+	}
+
+	resp, err := apply(ctx, user.GetId())
+	if err := apiError(ctx, resp, err); err != nil {
+		return fmt.Errorf("failed to %s okta user %q: %w", operation, idOrLogin, err)
+	}
+	return nil
+}
+
+// OffboardResult is the outcome of offboarding a single user.
+type OffboardResult struct {
+	UserID string
+	Login  string
+	// RemovedGroups are the names of the groups the user was removed from
+	RemovedGroups []string
+	// RemovedApps are the labels of the apps the user was unassigned from
+	RemovedApps []string
+	Deactivated bool
+	// Err is set when offboarding the user failed, the other fields show what was done before the failure
+	Err error
+}
+
+type offboardOptions struct {
+	deactivate bool
+}
+
+type OffboardOpt func(*offboardOptions)
+
+// WithDeactivate also deactivates offboarded users.
+func WithDeactivate(deactivate bool) OffboardOpt {
+	return func(o *offboardOptions) {
+		o.deactivate = deactivate
+	}
+}
+
+// OffboardUsers removes each user, by id or login, from all of their groups and direct app assignments.
+// App assignments granted through a group are removed with the group membership, groups managed
+// outside of Okta, such as the built in Everyone group and app groups, are left unchanged.
+// A failure for one user does not stop the others, the result for every user is returned
+// along with the joined errors.
+func (c *Client) OffboardUsers(ctx context.Context, users []string, opts ...OffboardOpt) ([]OffboardResult, error) {
+	options := &offboardOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	ctx = withRateLimitRecorder(ctx)
+
+	results := []OffboardResult{}
+	errs := []error{}
+	for _, idOrLogin := range users {
+		result := c.offboardUser(ctx, idOrLogin, options)
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("failed to offboard %q: %w", idOrLogin, result.Err))
+		}
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+func (c *Client) offboardUser(ctx context.Context, idOrLogin string, options *offboardOptions) OffboardResult {
+	result := OffboardResult{}
+
+	user, err := c.User(ctx, idOrLogin)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.UserID = user.GetId()
+	if user.Profile != nil {
+		result.Login = user.Profile.GetLogin()
+	}
+
+	groups := []okta.Group{}
+	for group, err := range All(ctx, c.UserAPI.ListUserGroups(ctx, user.GetId())) {
+		if err != nil {
+			result.Err = fmt.Errorf("failed to list okta user groups: %w", err)
+			return result
+		}
+		groups = append(groups, group)
+	}
+
+	for _, group := range groups {
+		if group.GetType() != "OKTA_GROUP" {
+			continue
+		}
+		resp, err := c.GroupAPI.UnassignUserFromGroup(ctx, group.GetId(), user.GetId()).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			result.Err = fmt.Errorf("failed to remove user from okta group %q: %w", groupName(group), err)
+			return result
+		}
+		result.RemovedGroups = append(result.RemovedGroups, groupName(group))
+	}
+
+	// app links only cover apps shown on the user's dashboard, so hidden and provisioning only apps are found by filtering
+	apps, err := c.ListApplications(ctx, WithFilter(Eq("user.id", user.GetId())))
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	for _, app := range apps {
+		appUser, resp, err := c.ApplicationUsersAPI.GetApplicationUser(ctx, app.ID, user.GetId()).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			result.Err = fmt.Errorf("failed to get okta app assignment for %q: %w", app.Label, err)
+			return result
+		}
+		// assignments through groups that could not be removed above cannot be unassigned directly
+		if appUser.GetScope() != "USER" {
+			continue
+		}
+
+		resp, err = c.ApplicationUsersAPI.UnassignUserFromApplication(ctx, app.ID, user.GetId()).Execute()
+		if err := apiError(ctx, resp, err); err != nil {
+			result.Err = fmt.Errorf("failed to unassign user from okta app %q: %w", app.Label, err)
+			return result
+		}
+		result.RemovedApps = append(result.RemovedApps, app.Label)
+	}
+
+	if options.deactivate {
+		if err := c.DeactivateUser(ctx, user.GetId()); err != nil {
+			result.Err = err
+			return result
+		}
+		result.Deactivated = true
+	}
+
+	return result
+}
@@ -0,0 +1,262 @@
+package okta
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	oktatest "github.com/kanopy-platform/go-library/providers/okta/testing"
+	"github.com/okta/okta-sdk-golang/v5/okta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newUsersServer(t *testing.T) (*Client, *oktatest.Server) {
+	return newSeededClient(t, oktatest.Fixtures{
+		Users: []oktatest.User{
+			{ID: "u1", Login: "alice@example.com"},
+			{ID: "u2", Login: "bob@example.com", Status: "STAGED"},
+			{ID: "u3", Login: "carol@example.com", Status: "SUSPENDED"},
+			{ID: "u4", Login: "dave@example.com", Status: "DEPROVISIONED"},
+		},
+		Groups: []oktatest.Group{{ID: "g1", Name: "admins"}, {ID: "g2", Name: "developers"}},
+		Apps:   []oktatest.App{{ID: "app1", Label: "wiki"}, {ID: "app2", Label: "chat"}},
+		Members: map[string][]string{
+			"admins":     {"u1"},
+			"developers": {"u1", "u2"},
+		},
+		AppGroups: map[string][]string{"chat": {"developers"}},
+		AppUsers:  map[string][]string{"wiki": {"u1", "u2"}},
+	})
+}
+
+func userStatus(t *testing.T, server *oktatest.Server, login string) string {
+	user, ok := server.User(login)
+	require.True(t, ok, "user %q should exist", login)
+	return user.Status
+}
+
+func TestUserLifecycle(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation func(*Client, context.Context, string) error
+		login     string
+		status    string
+		mutation  bool
+		invalid   bool
+	}{
+		{
+			name:      "activate staged",
+			operation: func(c *Client, ctx context.Context, id string) error { return c.ActivateUser(ctx, id, false) },
+			login:     "bob@example.com",
+			status:    "ACTIVE",
+			mutation:  true,
+		},
+		{
+			name:      "activate active is a no-op",
+			operation: func(c *Client, ctx context.Context, id string) error { return c.ActivateUser(ctx, id, false) },
+			login:     "alice@example.com",
+			status:    "ACTIVE",
+		},
+		{
+			name:      "activate suspended is invalid",
+			operation: func(c *Client, ctx context.Context, id string) error { return c.ActivateUser(ctx, id, false) },
+			login:     "carol@example.com",
+			status:    "SUSPENDED",
+			invalid:   true,
+		},
+		{
+			name:      "suspend active",
+			operation: (*Client).SuspendUser,
+			login:     "alice@example.com",
+			status:    "SUSPENDED",
+			mutation:  true,
+		},
+		{
+			name:      "suspend suspended is a no-op",
+			operation: (*Client).SuspendUser,
+			login:     "carol@example.com",
+			status:    "SUSPENDED",
+		},
+		{
+			name:      "suspend staged is invalid",
+			operation: (*Client).SuspendUser,
+			login:     "bob@example.com",
+			status:    "STAGED",
+			invalid:   true,
+		},
+		{
+			name:      "unsuspend suspended",
+			operation: (*Client).UnsuspendUser,
+			login:     "carol@example.com",
+			status:    "ACTIVE",
+			mutation:  true,
+		},
+		{
+			name:      "deactivate suspended",
+			operation: (*Client).DeactivateUser,
+			login:     "carol@example.com",
+			status:    "DEPROVISIONED",
+			mutation:  true,
+		},
+		{
+			name:      "deactivate deprovisioned is a no-op",
+			operation: (*Client).DeactivateUser,
+			login:     "dave@example.com",
+			status:    "DEPROVISIONED",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, server := newUsersServer(t)
+
+			err := test.operation(client, context.Background(), test.login)
+			if test.invalid {
+				var transitionErr *InvalidTransitionError
+				require.ErrorAs(t, err, &transitionErr)
+				assert.Equal(t, test.status, transitionErr.Status)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, test.status, userStatus(t, server, test.login))
+			assert.Equal(t, test.mutation, countRequests(server, "POST") > 0)
+		})
+	}
+}
+
+func TestUserNotFound(t *testing.T) {
+	client, _ := newUsersServer(t)
+	ctx := context.Background()
+
+	_, err := client.User(ctx, "missing@example.com")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.ErrorIs(t, client.SuspendUser(ctx, "missing@example.com"), ErrUserNotFound)
+	assert.NoError(t, client.DeleteUser(ctx, "missing@example.com"))
+}
+
+func TestCreateUser(t *testing.T) {
+	client, server := newUsersServer(t)
+	ctx := context.Background()
+
+	request := okta.CreateUserRequest{Profile: okta.UserProfile{}}
+	request.Profile.SetLogin("erin@example.com")
+	request.Profile.SetEmail("erin@example.com")
+
+	created, err := client.CreateUser(ctx, request, false)
+	require.NoError(t, err)
+	assert.Equal(t, "STAGED", created.GetStatus())
+
+	again, err := client.CreateUser(ctx, request, true)
+	require.NoError(t, err)
+	assert.Equal(t, created.GetId(), again.GetId())
+	assert.Equal(t, "ACTIVE", again.GetStatus())
+	assert.Equal(t, "ACTIVE", userStatus(t, server, "erin@example.com"))
+
+	posts := []string{}
+	for _, r := range server.Requests() {
+		if strings.HasPrefix(r, "POST") {
+			posts = append(posts, r)
+		}
+	}
+	assert.Equal(t, []string{"POST /api/v1/users", "POST /api/v1/users/" + created.GetId() + "/lifecycle/activate"}, posts)
+
+	// the existing user is looked up by login rather than searched for
+	assert.NotContains(t, server.Requests(), "GET /api/v1/users")
+}
+
+func TestDeleteUser(t *testing.T) {
+	client, server := newUsersServer(t)
+	ctx := context.Background()
+
+	require.NoError(t, client.DeleteUser(ctx, "alice@example.com"))
+	_, ok := server.User("alice@example.com")
+	assert.False(t, ok)
+	assert.Empty(t, server.Members("admins"))
+
+	require.NoError(t, client.DeleteUser(ctx, "dave@example.com"))
+	_, ok = server.User("dave@example.com")
+	assert.False(t, ok)
+}
+
+func TestOffboardUsers(t *testing.T) {
+	client, server := newUsersServer(t)
+
+	results, err := client.OffboardUsers(context.Background(), []string{"alice@example.com", "missing@example.com", "u2"}, WithDeactivate(true))
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.ErrorContains(t, err, `failed to offboard "missing@example.com"`)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, OffboardResult{
+		UserID:        "u1",
+		Login:         "alice@example.com",
+		RemovedGroups: []string{"admins", "developers"},
+		RemovedApps:   []string{"wiki"},
+		Deactivated:   true,
+	}, results[0])
+	assert.ErrorIs(t, results[1].Err, ErrUserNotFound)
+	assert.Equal(t, []string{"developers"}, results[2].RemovedGroups)
+	assert.Equal(t, []string{"wiki"}, results[2].RemovedApps)
+
+	assert.Empty(t, server.Members("admins"))
+	assert.Empty(t, server.Members("developers"))
+	assert.Empty(t, server.AppUsers("wiki"))
+	assert.Equal(t, "DEPROVISIONED", userStatus(t, server, "alice@example.com"))
+	assert.Equal(t, "DEPROVISIONED", userStatus(t, server, "bob@example.com"))
+}
+
+func TestOffboardUsersAppWithSeveralLinks(t *testing.T) {
+	client, server := newUsersServer(t)
+	server.Seed(oktatest.Fixtures{
+		Apps:     []oktatest.App{{ID: "app3", Label: "office", Links: 3}},
+		AppUsers: map[string][]string{"office": {"u1"}},
+	})
+
+	results, err := client.OffboardUsers(context.Background(), []string{"alice@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wiki", "office"}, results[0].RemovedApps)
+	assert.Empty(t, server.AppUsers("office"))
+	assert.Equal(t, 1, countRequests(server, "DELETE /api/v1/apps/app3/users/u1"))
+}
+
+func TestOffboardUsersAppWithoutLink(t *testing.T) {
+	client, server := newUsersServer(t)
+	server.Seed(oktatest.Fixtures{
+		Apps:     []oktatest.App{{ID: "app3", Label: "provisioning", Hidden: true}},
+		AppUsers: map[string][]string{"provisioning": {"u1", "u2"}},
+	})
+
+	results, err := client.OffboardUsers(context.Background(), []string{"alice@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wiki", "provisioning"}, results[0].RemovedApps)
+	assert.Equal(t, []string{"u2"}, server.AppUsers("provisioning"))
+}
+
+func TestUserMutationErrors(t *testing.T) {
+	client, server := newUsersServer(t)
+	server.InjectFault(oktatest.Fault{Method: http.MethodPost, PathPrefix: "/api/v1/users/u1/lifecycle", Status: http.StatusForbidden})
+
+	err := client.SuspendUser(context.Background(), "alice@example.com")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.NotEmpty(t, apiErr.RequestID)
+}
+
+func TestOffboardUsersGroupAssignedApp(t *testing.T) {
+	client, server := newUsersServer(t)
+
+	// chat is assigned through developers, which is removed, and carol's direct assignment to
+	// wiki is not affected by offboarding alice
+	server.AssignAppUsers("wiki", "u3")
+	results, err := client.OffboardUsers(context.Background(), []string{"alice@example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"wiki"}, results[0].RemovedApps)
+	assert.False(t, results[0].Deactivated)
+	assert.Equal(t, []string{"u2", "u3"}, server.AppUsers("wiki"))
+	assert.Equal(t, "ACTIVE", userStatus(t, server, "alice@example.com"))
+}
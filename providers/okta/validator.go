@@ -0,0 +1,411 @@
+package okta
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	jose "github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidToken is returned for access tokens that are malformed, expired, not yet valid, not signed by the
+	// authorization server or issued for another issuer or audience.
+	ErrInvalidToken = errors.New("invalid okta access token")
+	// ErrInsufficientScope is returned for valid access tokens that are missing a required scope.
+	ErrInsufficientScope = errors.New("okta access token is missing required scopes")
+)
+
+// defaultJWKSTimeout bounds requests for the signing keys made with the default HTTP client
+const defaultJWKSTimeout = 10 * time.Second
+
+// algorithms Okta authorization servers sign access tokens with
+var signatureAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.ES256, jose.ES384, jose.ES512}
+
+// Claims are the claims of a validated access token.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// ClientID is the cid claim, the client id of the app the token was issued to
+	ClientID string
+	// UserID is the uid claim, the Okta id of the user, empty for client credentials tokens
+	UserID string
+	// Scopes are read from the scp claim, or the space separated scope claim
+	Scopes []string
+	// Groups are read from the claim set with WithGroupsClaim, the authorization server must be
+	// configured to add the claim to access tokens
+	Groups []string
+	// Raw holds every claim in the token
+	Raw map[string]any
+}
+
+// HasScope reports whether the token was granted the scope.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+// InGroup reports whether the group claim includes group.
+func (c *Claims) InGroup(group string) bool {
+	return slices.Contains(c.Groups, group)
+}
+
+type claimsContextKey struct{}
+
+// ContextWithClaims returns a copy of ctx holding the claims, as TokenValidator.Middleware does.
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the request's validated access token.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// TokenValidator validates access tokens issued by an Okta authorization server. The authorization
+// server's signing keys are fetched from its JWKS endpoint and cached, a token signed with an unknown
+// key refreshes the keys so key rotation does not need a restart.
+/*
+
+Example usage:
+
+validator := okta.NewTokenValidator("https://example.okta.com/oauth2/default", "api://default",
+	okta.WithRequiredScopes("inventory.read"),
+)
+
+mux := http.NewServeMux()
+mux.Handle("/inventory", validator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	claims, _ := okta.ClaimsFromContext(r.Context())
+	fmt.Fprintf(w, "hello %s", claims.Subject)
+})))
+*/
+type TokenValidator struct {
+	issuer   string
+	audience string
+	// jwksURL is where the signing keys are fetched from, default: the issuer's keys endpoint
+	jwksURL        string
+	requiredScopes []string
+	// groupsClaim is the claim Claims.Groups is read from, default: groups
+	groupsClaim string
+	// leeway is the allowed clock skew when checking exp, iat and nbf, default: 1m
+	leeway time.Duration
+	// jwksTTL is how long fetched keys are used before they are fetched again, default: 1h
+	jwksTTL time.Duration
+	// jwksMinRefresh limits how often tokens with an unknown key id refresh the keys, default: 1m
+	jwksMinRefresh time.Duration
+	httpClient     *http.Client
+
+	// now is replaced in tests
+	now func() time.Time
+
+	mu      sync.Mutex
+	keys    jose.JSONWebKeySet
+	fetched time.Time
+	// refresh is the in-flight fetch of the keys, shared by every validation waiting for it
+	refresh *keysRefresh
+}
+
+type keysRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+type TokenValidatorOpt func(*TokenValidator)
+
+// WithJWKSURL sets the URL the authorization server's signing keys are fetched from.
+func WithJWKSURL(url string) TokenValidatorOpt {
+	return func(v *TokenValidator) {
+		v.jwksURL = url
+	}
+}
+
+// WithRequiredScopes rejects tokens that were not granted all of the scopes with ErrInsufficientScope.
+func WithRequiredScopes(scopes ...string) TokenValidatorOpt {
+	return func(v *TokenValidator) {
+		v.requiredScopes = scopes
+	}
+}
+
+// WithGroupsClaim sets the name of the claim holding the user's groups, default: groups.
+func WithGroupsClaim(claim string) TokenValidatorOpt {
+	return func(v *TokenValidator) {
+		v.groupsClaim = claim
+	}
+}
+
+// WithLeeway sets the clock skew allowed when checking the token's expiry and issue time, default: 1m.
+func WithLeeway(leeway time.Duration) TokenValidatorOpt {
+	return func(v *TokenValidator) {
+		v.leeway = leeway
+	}
+}
+
+// WithJWKSCacheTTL sets how long the signing keys are cached before they are fetched again, default: 1h.
+func WithJWKSCacheTTL(ttl time.Duration) TokenValidatorOpt {
+	return func(v *TokenValidator) {
+		v.jwksTTL = ttl
+	}
+}
+
+// WithValidatorHTTPClient sets the HTTP client used to fetch the signing keys, default: a client with a 10s timeout.
+func WithValidatorHTTPClient(client *http.Client) TokenValidatorOpt {
+	return func(v *TokenValidator) {
+		v.httpClient = client
+	}
+}
+
+// NewTokenValidator creates a TokenValidator for access tokens issued by issuer, e.g.
+// https://example.okta.com/oauth2/default, for audience, e.g. api://default.
+func NewTokenValidator(issuer, audience string, opts ...TokenValidatorOpt) *TokenValidator {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	v := &TokenValidator{
+		issuer:         issuer,
+		audience:       audience,
+		jwksURL:        issuer + "/v1/keys",
+		groupsClaim:    "groups",
+		leeway:         jwt.DefaultLeeway,
+		jwksTTL:        time.Hour,
+		jwksMinRefresh: time.Minute,
+		httpClient:     &http.Client{Timeout: defaultJWKSTimeout},
+		now:            time.Now,
+	}
+	// the org authorization server's keys are not under its issuer
+	if !strings.Contains(issuer, "/oauth2/") {
+		v.jwksURL = issuer + "/oauth2/v1/keys"
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Validate verifies the token's signature, issuer, audience, expiry and required scopes and returns its claims.
+// Invalid tokens return an error matching ErrInvalidToken or ErrInsufficientScope, other errors
+// mean the signing keys could not be fetched.
+func (v *TokenValidator) Validate(ctx context.Context, token string) (*Claims, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	key, err := v.signingKey(ctx, parsed.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	standard := jwt.Claims{}
+	raw := map[string]any{}
+	if err := parsed.Claims(key.Key, &standard, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	expected := jwt.Expected{Issuer: v.issuer, AnyAudience: jwt.Audience{v.audience}, Time: v.now()}
+	if err := standard.ValidateWithLeeway(expected, v.leeway); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	// exp is optional for JWTs but not for access tokens
+	if standard.Expiry == nil {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+
+	claims := &Claims{
+		Issuer:    standard.Issuer,
+		Subject:   standard.Subject,
+		Audience:  standard.Audience,
+		ExpiresAt: standard.Expiry.Time(),
+		ClientID:  stringClaim(raw["cid"]),
+		UserID:    stringClaim(raw["uid"]),
+		Scopes:    stringsClaim(raw["scp"]),
+		Groups:    stringsClaim(raw[v.groupsClaim]),
+		Raw:       raw,
+	}
+	if standard.IssuedAt != nil {
+		claims.IssuedAt = standard.IssuedAt.Time()
+	}
+	if scope, ok := raw["scope"].(string); ok && len(claims.Scopes) == 0 {
+		claims.Scopes = strings.Fields(scope)
+	}
+
+	missing := []string{}
+	for _, scope := range v.requiredScopes {
+		if !claims.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return claims, fmt.Errorf("%w %q", ErrInsufficientScope, missing)
+	}
+
+	return claims, nil
+}
+
+// signingKey returns the key with the key id, fetching the keys when the cache has expired or
+// does not have the key and was not refreshed in the last jwksMinRefresh.
+// Concurrent validations share a single fetch, which is made without holding v.mu, and stop waiting
+// for it when their ctx is done.
+func (v *TokenValidator) signingKey(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	v.mu.Lock()
+	age := v.now().Sub(v.fetched)
+	keys := v.keys.Key(kid)
+
+	if !v.fetched.IsZero() && age < v.jwksTTL && (len(keys) > 0 || age < v.jwksMinRefresh) {
+		v.mu.Unlock()
+		return signingKey(keys, kid)
+	}
+
+	refresh := v.refresh
+	if refresh == nil {
+		refresh = &keysRefresh{done: make(chan struct{})}
+		v.refresh = refresh
+		// the fetch is shared so it must not be canceled with the validation that started it
+		go v.refreshKeys(context.WithoutCancel(ctx), refresh)
+	}
+	v.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to fetch okta signing keys: %w", ctx.Err())
+	case <-refresh.done:
+	}
+
+	v.mu.Lock()
+	keys = v.keys.Key(kid)
+	v.mu.Unlock()
+
+	if refresh.err != nil {
+		if len(keys) == 0 {
+			return nil, refresh.err
+		}
+		log.Warnf("failed to refresh okta signing keys, using cached keys: %s", refresh.err)
+	}
+	return signingKey(keys, kid)
+}
+
+// refreshKeys fetches the keys into the cache and completes refresh, even if the fetch panics.
+func (v *TokenValidator) refreshKeys(ctx context.Context, refresh *keysRefresh) {
+	var fetched *jose.JSONWebKeySet
+	defer func() {
+		if r := recover(); r != nil {
+			refresh.err = fmt.Errorf("failed to fetch okta signing keys: panic: %v", r)
+		}
+
+		v.mu.Lock()
+		if refresh.err == nil {
+			v.keys = *fetched
+			v.fetched = v.now()
+		}
+		v.refresh = nil
+		v.mu.Unlock()
+		close(refresh.done)
+	}()
+
+	fetched, refresh.err = v.fetchKeys(ctx)
+}
+
+func signingKey(keys []jose.JSONWebKey, kid string) (*jose.JSONWebKey, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, kid)
+	}
+	return &keys[0], nil
+}
+
+func (v *TokenValidator) fetchKeys(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build okta keys request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch okta signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch okta signing keys: %s", resp.Status)
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	if err := json.NewDecoder(resp.Body).Decode(keys); err != nil {
+		return nil, fmt.Errorf("failed to decode okta signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Middleware validates the bearer token of each request and passes requests with a valid token to next,
+// with the token's claims in the request context, see ClaimsFromContext. Requests without a valid token
+// are rejected with 401, tokens missing a required scope with 403, and 503 is returned when the signing
+// keys cannot be fetched.
+func (v *TokenValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		claims, err := v.Validate(r.Context(), token)
+		switch {
+		case errors.Is(err, ErrInsufficientScope):
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_scope", scope=%q`, strings.Join(v.requiredScopes, " ")))
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		case errors.Is(err, ErrInvalidToken):
+			log.Debugf("rejected okta access token: %s", err)
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		case err != nil:
+			log.Errorf("unable to validate okta access token: %s", err)
+			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+func stringClaim(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// stringsClaim returns a claim holding a string or an array of strings as a slice.
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
-	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/trinodb/trino-go-client/trino"
@@ -137,8 +136,10 @@ type Client struct {
 	dsn string
 	// conn is the SQL database connection to the Trino server.
 	conn *sql.DB
-	// retryCount is the number of times to retry a query in case of failure, default 5
-	retryCount int
+	// retry is the policy used to retry failed queries, default: DefaultRetryPolicy
+	retry RetryPolicy
+	// execRetry is the policy used to retry failed statements run with Exec, see Client.execRetryPolicy
+	execRetry *RetryPolicy
 }
 
 func New(uri string, opts ...option) (*Client, error) {
@@ -146,7 +147,7 @@ func New(uri string, opts ...option) (*Client, error) {
 		config: trino.Config{
 			ServerURI: uri,
 		},
-		retryCount: 5, // default retry count
+		retry: DefaultRetryPolicy(),
 	}
 
 	for _, opt := range opts {
@@ -187,7 +188,23 @@ func (c *Client) Disconnect() {
 	log.Info("Connection to Trino closed")
 }
 
+// Query runs the statement with the args, retrying failures according to the client's RetryPolicy.
+// Retries stop as soon as ctx is done.
 func (c *Client) Query(ctx context.Context, statement string, args ...any) (*sql.Rows, error) {
+	stmt, err := c.prepare(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
+	// this cannot return an error for trino
+	defer stmt.Close() //nolint:errcheck
+
+	return retry(ctx, c.retry, "query", func() (*sql.Rows, error) {
+		return stmt.QueryContext(ctx, args...)
+	})
+}
+
+// prepare connects to Trino if needed and prepares the statement.
+func (c *Client) prepare(ctx context.Context, statement string) (*sql.Stmt, error) {
 	if c.conn == nil {
 		if e := c.Connect(); e != nil {
 			return nil, e
@@ -200,23 +217,7 @@ func (c *Client) Query(ctx context.Context, statement string, args ...any) (*sql
 		// this is unreavchable for the trino driver but we handle it to keep the linter happy
 		return nil, fmt.Errorf("prepare Error: %s", err)
 	}
-	// this cannot return an error for trino
-	defer stmt.Close() //nolint:errcheck
-
-	count := 0
-	for {
-		out, err := stmt.QueryContext(ctx, args...)
-		if err == nil {
-			return out, nil
-		}
-
-		count++
-		if count > c.retryCount {
-			return nil, fmt.Errorf("query failed after %d attempts: %w", count, err)
-		}
-
-		time.Sleep(time.Second * 2 * time.Duration(count)) // exponential backoff
-	}
+	return stmt, nil
 }
 
 type option func(*Client) error
@@ -252,9 +253,24 @@ func WithCustomClient(name string, customClient *http.Client) option {
 	}
 }
 
+// WithRetryCount sets the number of times a failed query is retried, keeping the rest of the retry policy.
 func WithRetryCount(retryCount int) option {
 	return func(c *Client) error {
-		c.retryCount = retryCount
+		if retryCount < 0 {
+			return fmt.Errorf("retry count must not be negative")
+		}
+		c.retry.MaxRetries = retryCount
+		return nil
+	}
+}
+
+// WithRetryPolicy replaces the policy used to retry failed queries, see DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) option {
+	return func(c *Client) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		c.retry = policy
 		return nil
 	}
 }
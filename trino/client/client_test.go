@@ -156,8 +156,8 @@ func TestClientRetry(t *testing.T) {
 		require.NoError(t, err, name)
 
 		client := &Client{
-			conn:       db,
-			retryCount: tc.retryCount,
+			conn:  db,
+			retry: testRetryPolicy(tc.retryCount),
 		}
 
 		rows, err := client.Query(ctx, "SELECT 1")
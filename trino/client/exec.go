@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Exec runs a statement that does not return rows, such as INSERT, CREATE TABLE AS, DELETE or CALL,
+// and returns the number of rows it affected. Statements Trino does not report a count for, e.g. CREATE TABLE
+// or CALL, return 0.
+//
+// A statement that fails after Trino accepted it may have partially run, so unlike Query, Exec only retries
+// errors showing that the statement never reached Trino, see IsExecRetryable and WithExecRetryPolicy.
+// Use ExecIdempotent for statements that are safe to run more than once.
+/*
+
+Example usage:
+
+inserted, err := client.Exec(ctx, "INSERT INTO orders_archive SELECT * FROM orders WHERE created_at < ?", cutoff)
+
+_, err = client.ExecIdempotent(ctx, "CREATE TABLE IF NOT EXISTS orders_archive (LIKE orders)")
+*/
+func (c *Client) Exec(ctx context.Context, statement string, args ...any) (int64, error) {
+	return c.exec(ctx, c.execRetryPolicy(), statement, args...)
+}
+
+// ExecIdempotent runs a statement like Exec, retrying failures with the client's query RetryPolicy.
+// It must only be used for statements that have the same effect when they run more than once,
+// e.g. CREATE TABLE IF NOT EXISTS, DROP TABLE IF EXISTS or DELETE.
+func (c *Client) ExecIdempotent(ctx context.Context, statement string, args ...any) (int64, error) {
+	return c.exec(ctx, c.retry, statement, args...)
+}
+
+func (c *Client) exec(ctx context.Context, policy RetryPolicy, statement string, args ...any) (int64, error) {
+	stmt, err := c.prepare(ctx, statement)
+	if err != nil {
+		return 0, err
+	}
+	// this cannot return an error for trino
+	defer stmt.Close() //nolint:errcheck
+
+	result, err := retry(ctx, policy, "statement", func() (sql.Result, error) {
+		return stmt.ExecContext(ctx, args...)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read affected rows: %w", err)
+	}
+	return affected, nil
+}
+
+// execRetryPolicy returns the policy set with WithExecRetryPolicy, or the query policy retrying
+// only the errors accepted by IsExecRetryable.
+func (c *Client) execRetryPolicy() RetryPolicy {
+	if c.execRetry != nil {
+		return *c.execRetry
+	}
+	policy := c.retry
+	policy.Retryable = IsExecRetryable
+	return policy
+}
+
+// WithExecRetryPolicy replaces the policy used to retry statements run with Exec.
+// By default Exec uses the query retry policy set with WithRetryPolicy or WithRetryCount,
+// with IsExecRetryable as its classifier.
+func WithExecRetryPolicy(policy RetryPolicy) option {
+	return func(c *Client) error {
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		c.execRetry = &policy
+		return nil
+	}
+}
+
+// IsExecRetryable is the default classifier of Exec. It only retries errors that show the statement was
+// never sent to Trino, failures to resolve or connect to the server, as any other error may have been
+// returned after the statement started to modify data.
+func IsExecRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
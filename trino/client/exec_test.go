@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trinodb/trino-go-client/trino"
+
+	mocktrino "github.com/kanopy-platform/go-library/trino/testing"
+)
+
+func dialError() error {
+	return &trino.ErrQueryFailed{Reason: &url.Error{Op: "Post", URL: "https://trino.example.com/v1/statement", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}}
+}
+
+func TestIsExecRetryable(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"nil":            {err: nil, retryable: false},
+		"dial":           {err: dialError(), retryable: true},
+		"dns":            {err: fmt.Errorf("post: %w", &net.DNSError{Name: "trino.example.com", IsNotFound: true}), retryable: true},
+		"read":           {err: &trino.ErrQueryFailed{Reason: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset")}}, retryable: false},
+		"cluster error":  {err: &trino.ErrQueryFailed{StatusCode: 200, Reason: &trino.ErrTrino{ErrorType: "INSUFFICIENT_RESOURCES"}}, retryable: false},
+		"server error":   {err: &trino.ErrQueryFailed{StatusCode: 500, Reason: errors.New("oops")}, retryable: false},
+		"unknown":        {err: errors.New("mock error"), retryable: false},
+		"context cancel": {err: context.Canceled, retryable: false},
+	}
+
+	for name, tc := range testcases {
+		assert.Equal(t, tc.retryable, IsExecRetryable(tc.err), name)
+	}
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	mockErr := errors.New("mock error")
+
+	t.Run("affected rows", func(t *testing.T) {
+		t.Parallel()
+
+		client := newMockClient(t, testRetryPolicy(3), &mocktrino.MockStmt{
+			Err:     []error{dialError(), nil},
+			Results: []driver.Result{nil, driver.RowsAffected(12)},
+		})
+
+		affected, err := client.Exec(ctx, "INSERT INTO orders_archive SELECT * FROM orders")
+		require.NoError(t, err)
+		assert.Equal(t, int64(12), affected)
+	})
+
+	t.Run("not retried by default", func(t *testing.T) {
+		t.Parallel()
+
+		client := newMockClient(t, testRetryPolicy(3), &mocktrino.MockStmt{
+			Err:     []error{mockErr, nil},
+			Results: []driver.Result{nil, driver.RowsAffected(12)},
+		})
+
+		affected, err := client.Exec(ctx, "INSERT INTO orders_archive SELECT * FROM orders")
+		assert.ErrorIs(t, err, mockErr)
+		assert.ErrorContains(t, err, "statement failed after 1 attempts")
+		assert.Zero(t, affected)
+	})
+
+	t.Run("results without errors", func(t *testing.T) {
+		t.Parallel()
+
+		client := newMockClient(t, testRetryPolicy(0), &mocktrino.MockStmt{
+			Results: []driver.Result{driver.RowsAffected(5)},
+		})
+
+		affected, err := client.Exec(ctx, "DELETE FROM orders WHERE id = 1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), affected)
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		t.Parallel()
+
+		client := newMockClient(t, testRetryPolicy(3), &mocktrino.MockStmt{
+			Err:     []error{mockErr, nil},
+			Results: []driver.Result{nil, driver.RowsAffected(0)},
+		})
+
+		_, err := client.ExecIdempotent(ctx, "CREATE TABLE IF NOT EXISTS orders_archive (LIKE orders)")
+		assert.NoError(t, err)
+	})
+
+	t.Run("exec retry policy", func(t *testing.T) {
+		t.Parallel()
+
+		client := newMockClient(t, testRetryPolicy(0), &mocktrino.MockStmt{
+			Err:     []error{mockErr, nil},
+			Results: []driver.Result{nil, driver.RowsAffected(3)},
+		})
+		policy := testRetryPolicy(1)
+		policy.Retryable = func(err error) bool { return errors.Is(err, mockErr) }
+		require.NoError(t, WithExecRetryPolicy(policy)(client))
+
+		affected, err := client.Exec(ctx, "DELETE FROM orders WHERE id = 1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), affected)
+	})
+}
+
+func TestExecRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(defaultTestURI(), WithRetryCount(2))
+	require.NoError(t, err)
+
+	policy := c.execRetryPolicy()
+	assert.Equal(t, 2, policy.MaxRetries, "exec retries follow the query policy")
+	assert.False(t, policy.retryable(errors.New("mock error")))
+	assert.True(t, c.retry.retryable(errors.New("mock error")))
+
+	_, err = New(defaultTestURI(), WithExecRetryPolicy(RetryPolicy{MaxRetries: -1}))
+	assert.Error(t, err)
+}
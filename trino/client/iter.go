@@ -0,0 +1,133 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+	"time"
+)
+
+// QueryStats reports the progress of a query streamed with QueryIter.
+// It is safe to read from another goroutine while the query is being read, e.g. to report progress.
+type QueryStats struct {
+	mu       sync.Mutex
+	rowsRead int64
+	started  time.Time
+	finished time.Time
+}
+
+// RowsRead returns the number of rows scanned so far.
+func (s *QueryStats) RowsRead() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rowsRead
+}
+
+// Elapsed returns the time since the query started, or the time it ran for once it is done.
+// It is 0 until the query starts.
+func (s *QueryStats) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.started.IsZero():
+		return 0
+	case s.finished.IsZero():
+		return time.Since(s.started)
+	}
+	return s.finished.Sub(s.started)
+}
+
+// Done reports whether the query has finished, because all its rows were read, it failed, or the loop stopped early.
+func (s *QueryStats) Done() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.finished.IsZero()
+}
+
+func (s *QueryStats) start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rowsRead = 0
+	s.started = time.Now()
+	s.finished = time.Time{}
+}
+
+func (s *QueryStats) read() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rowsRead++
+}
+
+func (s *QueryStats) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.finished = time.Now()
+}
+
+// QueryIter returns an iterator that runs the statement with Client.Query and streams its rows, scanned into
+// a T as described by QueryInto, along with the stats of the query. The statement runs each time the iterator
+// is ranged over, which resets the stats.
+//
+// Rows are fetched from Trino as the loop consumes them, so results larger than memory can be processed
+// at the pace of the consumer. The query and its rows are closed when the loop ends, including when it stops
+// early with break or return. An error ends the iteration, it is yielded with the zero value of T.
+/*
+
+Example usage:
+
+orders, stats := QueryIter[Order](ctx, client, "SELECT * FROM orders WHERE created_at > ?", since)
+
+for order, err := range orders {
+	if err != nil {
+		return err
+	}
+	if err := export(order); err != nil {
+		return err
+	}
+	if stats.RowsRead()%1_000_000 == 0 {
+		log.Infof("exported %d orders in %s", stats.RowsRead(), stats.Elapsed())
+	}
+}
+*/
+func QueryIter[T any](ctx context.Context, c *Client, statement string, args ...any) (iter.Seq2[T, error], *QueryStats) {
+	stats := &QueryStats{}
+
+	seq := func(yield func(T, error) bool) {
+		var zero T
+
+		stats.start()
+		defer stats.finish()
+
+		rows, err := c.Query(ctx, statement, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close() //nolint:errcheck
+
+		scanner, err := newRowScanner[T](rows)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for rows.Next() {
+			var value T
+			if err := scanner.scan(rows, &value); err != nil {
+				yield(zero, err)
+				return
+			}
+			stats.read()
+			if !yield(value, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, fmt.Errorf("failed to read rows: %w", err))
+		}
+	}
+
+	return seq, stats
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mocktrino "github.com/kanopy-platform/go-library/trino/testing"
+)
+
+func TestQueryIter(t *testing.T) {
+	t.Parallel()
+
+	stub := &mocktrino.StubRows{
+		Cols:   []string{"id"},
+		Values: [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}},
+	}
+	client := newMockClient(t, testRetryPolicy(0), &mocktrino.MockStmt{
+		Rows: []*mocktrino.StubRows{stub},
+		Err:  []error{nil},
+	})
+
+	rows, stats := QueryIter[int64](context.Background(), client, "SELECT id FROM orders")
+	assert.False(t, stats.Done())
+	assert.Zero(t, stats.Elapsed())
+
+	ids := []int64{}
+	for id, err := range rows {
+		require.NoError(t, err)
+		ids = append(ids, id)
+		assert.Equal(t, int64(len(ids)), stats.RowsRead())
+		assert.False(t, stats.Done())
+
+		if len(ids) == 2 {
+			break
+		}
+	}
+
+	assert.Equal(t, []int64{1, 2}, ids)
+	assert.True(t, stub.Closed, "rows are closed when the loop stops early")
+	assert.True(t, stats.Done())
+	assert.Equal(t, int64(2), stats.RowsRead())
+	assert.Positive(t, stats.Elapsed())
+	assert.Equal(t, stats.Elapsed(), stats.Elapsed(), "elapsed stops when the query is done")
+}
+
+func TestQueryIterErrors(t *testing.T) {
+	t.Parallel()
+
+	mockErr := errors.New("mock error")
+	client := newMockClient(t, testRetryPolicy(0), &mocktrino.MockStmt{
+		Rows: []*mocktrino.StubRows{nil},
+		Err:  []error{mockErr},
+	})
+
+	rows, stats := QueryIter[int64](context.Background(), client, "SELECT id FROM orders")
+	count := 0
+	for id, err := range rows {
+		count++
+		assert.ErrorIs(t, err, mockErr)
+		assert.Zero(t, id)
+	}
+	assert.Equal(t, 1, count)
+	assert.True(t, stats.Done())
+	assert.Zero(t, stats.RowsRead())
+
+	client = newRowsClient(t, []string{"id"}, []driver.Value{int64(1)}, []driver.Value{"two"})
+	rows, stats = QueryIter[int64](context.Background(), client, "SELECT id FROM orders")
+	errs := []error{}
+	for _, err := range rows {
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.ErrorContains(t, errs[1], `failed to scan column "id" into int64`)
+	assert.Equal(t, int64(1), stats.RowsRead())
+}
@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// RetryPolicy controls how the Client retries failed statements.
+// Waits between attempts grow exponentially from InitialInterval by Multiplier up to MaxInterval,
+// and are randomized by Jitter so that clients failing together do not retry together.
+/*
+
+Example usage:
+
+policy := DefaultRetryPolicy()
+policy.MaxRetries = 3
+policy.MaxElapsedTime = time.Minute
+
+client, err := New(serverURI, WithRetryPolicy(policy))
+*/
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed statement is retried, 0 disables retries, default: 5
+	MaxRetries int
+	// InitialInterval is the wait before the first retry, default: 1s
+	InitialInterval time.Duration
+	// MaxInterval caps the wait between retries, default: 30s
+	MaxInterval time.Duration
+	// Multiplier is the factor each wait grows by, values below 1 are treated as 1, default: 2
+	Multiplier float64
+	// Jitter randomizes each wait by up to this fraction of it in either direction, between 0 and 1, default: 0.2
+	Jitter float64
+	// MaxElapsedTime stops retrying when the next attempt would start after this long since the first one,
+	// 0 means no limit, default: 2m
+	MaxElapsedTime time.Duration
+	// Retryable reports whether an error is worth retrying, default: IsRetryable
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used by New.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:      5,
+		InitialInterval: time.Second,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxElapsedTime:  2 * time.Minute,
+		Retryable:       IsRetryable,
+	}
+}
+
+func (p RetryPolicy) validate() error {
+	switch {
+	case p.MaxRetries < 0:
+		return fmt.Errorf("retry policy MaxRetries must not be negative")
+	case p.InitialInterval < 0 || p.MaxInterval < 0 || p.MaxElapsedTime < 0:
+		return fmt.Errorf("retry policy intervals must not be negative")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("retry policy Jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// backoff returns the wait before the nth retry, starting at 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	wait := float64(p.InitialInterval) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxInterval > 0 {
+		wait = math.Min(wait, float64(p.MaxInterval))
+	}
+	wait *= 1 + p.Jitter*(2*rand.Float64()-1)
+
+	return time.Duration(wait)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable == nil {
+		return IsRetryable(err)
+	}
+	return p.Retryable(err)
+}
+
+// retry calls fn until it succeeds, returns an error the policy does not retry, or the policy is exhausted.
+// Waits between attempts end as soon as ctx is done. op names what fn runs in the returned errors, e.g. "query".
+func retry[T any](ctx context.Context, p RetryPolicy, op string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		out, err := fn()
+		if err == nil {
+			return out, nil
+		}
+
+		if attempt > p.MaxRetries || !p.retryable(err) {
+			return out, fmt.Errorf("%s failed after %d attempts: %w", op, attempt, err)
+		}
+
+		wait := p.backoff(attempt)
+		if p.MaxElapsedTime > 0 && time.Since(start)+wait > p.MaxElapsedTime {
+			return out, fmt.Errorf("%s failed after %d attempts, retry time limit %s reached: %w", op, attempt, p.MaxElapsedTime, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return out, fmt.Errorf("%s failed after %d attempts: %w: %w", op, attempt, context.Cause(ctx), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// IsRetryable is the default RetryPolicy classifier. It does not retry context errors, cancelled queries,
+// errors Trino attributes to the user such as syntax errors, missing tables or permission denied,
+// or HTTP client errors other than 408 and 429. Cluster errors, e.g. insufficient resources or failed
+// workers, HTTP server errors and network errors are retried, as are errors it does not recognize.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	switch {
+	case errors.Is(err, trino.ErrQueryCancelled),
+		errors.Is(err, trino.ErrOperationNotSupported),
+		errors.Is(err, trino.ErrUnsupportedHeader),
+		errors.Is(err, trino.ErrInvalidResponseType),
+		errors.Is(err, trino.ErrInvalidProgressCallbackHeader):
+		return false
+	}
+
+	// query errors are reported with the status of the response they were read from, check them first
+	var trinoErr *trino.ErrTrino
+	if errors.As(err, &trinoErr) {
+		return trinoErr.ErrorType != "USER_ERROR"
+	}
+
+	var queryErr *trino.ErrQueryFailed
+	if errors.As(err, &queryErr) && queryErr.StatusCode != 0 {
+		switch queryErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		}
+		return queryErr.StatusCode >= http.StatusInternalServerError || queryErr.StatusCode < http.StatusBadRequest
+	}
+
+	return true
+}
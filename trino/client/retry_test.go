@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trinodb/trino-go-client/trino"
+
+	mocktrino "github.com/kanopy-platform/go-library/trino/testing"
+)
+
+// testRetryPolicy returns a policy that retries without waiting.
+func testRetryPolicy(maxRetries int) RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.MaxRetries = maxRetries
+	policy.InitialInterval = time.Millisecond
+	policy.MaxInterval = time.Millisecond
+	return policy
+}
+
+var mockDrivers atomic.Int32
+
+// newMockClient returns a client whose queries are answered by stmt.
+func newMockClient(t *testing.T, policy RetryPolicy, stmt *mocktrino.MockStmt) *Client {
+	name := fmt.Sprintf("mock_trino_retry_%d", mockDrivers.Add(1))
+	sql.Register(name, &mocktrino.MockDriver{Conn: &mocktrino.MockConn{Stmt: stmt}})
+
+	db, err := sql.Open(name, "mock://")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() }) //nolint:errcheck
+
+	return &Client{conn: db, retry: policy}
+}
+
+func userError(message string) error {
+	return &trino.ErrQueryFailed{StatusCode: http.StatusOK, Reason: &trino.ErrTrino{ErrorType: "USER_ERROR", ErrorName: "SYNTAX_ERROR", Message: message}}
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"nil":                {err: nil, retryable: false},
+		"unknown":            {err: errors.New("connection reset"), retryable: true},
+		"context canceled":   {err: fmt.Errorf("query: %w", context.Canceled), retryable: false},
+		"deadline exceeded":  {err: context.DeadlineExceeded, retryable: false},
+		"query cancelled":    {err: trino.ErrQueryCancelled, retryable: false},
+		"user error":         {err: userError("mismatched input"), retryable: false},
+		"insufficient":       {err: &trino.ErrQueryFailed{StatusCode: http.StatusOK, Reason: &trino.ErrTrino{ErrorType: "INSUFFICIENT_RESOURCES"}}, retryable: true},
+		"internal error":     {err: &trino.ErrQueryFailed{StatusCode: http.StatusOK, Reason: &trino.ErrTrino{ErrorType: "INTERNAL_ERROR"}}, retryable: true},
+		"network error":      {err: &trino.ErrQueryFailed{Reason: errors.New("dial tcp: connection refused")}, retryable: true},
+		"unauthorized":       {err: &trino.ErrQueryFailed{StatusCode: http.StatusUnauthorized, Reason: errors.New("unauthorized")}, retryable: false},
+		"too many requests":  {err: &trino.ErrQueryFailed{StatusCode: http.StatusTooManyRequests, Reason: errors.New("slow down")}, retryable: true},
+		"server error":       {err: &trino.ErrQueryFailed{StatusCode: http.StatusInternalServerError, Reason: errors.New("oops")}, retryable: true},
+		"unsupported header": {err: trino.ErrUnsupportedHeader, retryable: false},
+	}
+
+	for name, tc := range testcases {
+		assert.Equal(t, tc.retryable, IsRetryable(tc.err), name)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{InitialInterval: time.Second, MaxInterval: 10 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 8*time.Second, policy.backoff(4))
+	assert.Equal(t, 10*time.Second, policy.backoff(5))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		wait := policy.backoff(2)
+		assert.GreaterOrEqual(t, wait, time.Second)
+		assert.LessOrEqual(t, wait, 3*time.Second)
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	c, err := New(defaultTestURI(), WithRetryCount(2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, c.retry.MaxRetries)
+	assert.Equal(t, DefaultRetryPolicy().InitialInterval, c.retry.InitialInterval)
+
+	policy := testRetryPolicy(1)
+	c, err = New(defaultTestURI(), WithRetryPolicy(policy))
+	require.NoError(t, err)
+	assert.Equal(t, policy.MaxRetries, c.retry.MaxRetries)
+
+	policy.Jitter = 2
+	_, err = New(defaultTestURI(), WithRetryPolicy(policy))
+	assert.Error(t, err)
+
+	_, err = New(defaultTestURI(), WithRetryCount(-1))
+	assert.Error(t, err)
+}
+
+func TestQueryRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	mockErr := errors.New("mock error")
+
+	t.Run("user errors are not retried", func(t *testing.T) {
+		t.Parallel()
+
+		client := newMockClient(t, testRetryPolicy(3), &mocktrino.MockStmt{
+			Rows: []*mocktrino.StubRows{nil, {}},
+			Err:  []error{userError("mismatched input"), nil},
+		})
+
+		rows, err := client.Query(context.Background(), "SELEC 1")
+		assert.Nil(t, rows)
+		assert.ErrorContains(t, err, "query failed after 1 attempts")
+		var trinoErr *trino.ErrTrino
+		assert.ErrorAs(t, err, &trinoErr)
+	})
+
+	t.Run("custom classifier", func(t *testing.T) {
+		t.Parallel()
+
+		policy := testRetryPolicy(3)
+		policy.Retryable = func(err error) bool { return !errors.Is(err, mockErr) }
+		client := newMockClient(t, policy, &mocktrino.MockStmt{
+			Rows: []*mocktrino.StubRows{nil, {}},
+			Err:  []error{mockErr, nil},
+		})
+
+		_, err := client.Query(context.Background(), "SELECT 1")
+		assert.ErrorIs(t, err, mockErr)
+	})
+
+	t.Run("context done while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		policy := testRetryPolicy(3)
+		policy.InitialInterval = time.Hour
+		policy.MaxInterval = time.Hour
+		policy.MaxElapsedTime = 0
+		client := newMockClient(t, policy, &mocktrino.MockStmt{
+			Rows: []*mocktrino.StubRows{nil, {}},
+			Err:  []error{mockErr, nil},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := client.Query(ctx, "SELECT 1")
+		assert.Less(t, time.Since(start), 10*time.Second)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, err, mockErr)
+	})
+
+	t.Run("max elapsed time", func(t *testing.T) {
+		t.Parallel()
+
+		policy := testRetryPolicy(3)
+		policy.InitialInterval = time.Hour
+		policy.MaxInterval = time.Hour
+		policy.MaxElapsedTime = time.Minute
+		client := newMockClient(t, policy, &mocktrino.MockStmt{
+			Rows: []*mocktrino.StubRows{nil, {}},
+			Err:  []error{mockErr, nil},
+		})
+
+		_, err := client.Query(context.Background(), "SELECT 1")
+		assert.ErrorContains(t, err, "retry time limit 1m0s reached")
+		assert.ErrorIs(t, err, mockErr)
+	})
+}
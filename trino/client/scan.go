@@ -0,0 +1,460 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/trinodb/trino-go-client/trino"
+)
+
+// ErrMultipleRows is returned by QueryOne when the statement returns more than one row.
+var ErrMultipleRows = errors.New("query returned more than one row")
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+)
+
+// QueryInto runs the statement with Client.Query and scans every row into a T.
+// Use QueryIter to stream results too large to hold in memory.
+//
+// When T is a struct, each column is scanned into the field tagged with its name, e.g. `trino:"created_at"`,
+// or the exported field whose name matches the column case insensitively. Fields of embedded structs are
+// promoted and fields tagged `trino:"-"` are ignored. Every column must have a field and every tagged field
+// must have a column, so a renamed column fails the query instead of leaving a field empty.
+// Any other T, including time.Time and sql.Scanner implementations, is scanned from a single column.
+//
+// Values are converted the way the Trino driver returns them:
+//   - NULL can only be scanned into pointers, slices, maps, interfaces and sql.Scanner implementations
+//     such as sql.NullString or trino.NullTime
+//   - arrays are scanned into slices and maps into maps, whose keys are converted from strings
+//   - rows are scanned into structs field by field in declaration order, as Trino does not return field names
+//   - timestamps, including those with time zones and those nested in arrays, maps and rows, into time.Time
+//   - decimals are returned as strings to preserve their precision and can be scanned into strings,
+//     floats, or a decimal type implementing sql.Scanner
+/*
+
+Example usage:
+
+type Order struct {
+	ID        int64            `trino:"id"`
+	Total     string           `trino:"total"`
+	Tags      []string         `trino:"tags"`
+	Discount  *float64         `trino:"discount"`
+	Attrs     map[string]int64 `trino:"attrs"`
+	CreatedAt time.Time        `trino:"created_at"`
+}
+
+orders, err := QueryInto[Order](ctx, client, "SELECT id, total, tags, discount, attrs, created_at FROM orders WHERE region = ?", "us")
+
+count, err := QueryOne[int64](ctx, client, "SELECT count(*) FROM orders")
+*/
+func QueryInto[T any](ctx context.Context, c *Client, statement string, args ...any) ([]T, error) {
+	rows, _ := QueryIter[T](ctx, c, statement, args...)
+
+	out := []T{}
+	for value, err := range rows {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, value)
+	}
+
+	return out, nil
+}
+
+// QueryOne runs the statement with Client.Query and scans its only row into a T, see QueryInto.
+// It returns an error wrapping sql.ErrNoRows when there are no rows and ErrMultipleRows when there is more than one.
+func QueryOne[T any](ctx context.Context, c *Client, statement string, args ...any) (T, error) {
+	var value T
+
+	rows, err := c.Query(ctx, statement, args...)
+	if err != nil {
+		return value, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	scanner, err := newRowScanner[T](rows)
+	if err != nil {
+		return value, err
+	}
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return value, fmt.Errorf("failed to read rows: %w", err)
+		}
+		return value, fmt.Errorf("failed to query one row: %w", sql.ErrNoRows)
+	}
+	if err := scanner.scan(rows, &value); err != nil {
+		return value, err
+	}
+	if rows.Next() {
+		return value, ErrMultipleRows
+	}
+	if err := rows.Err(); err != nil {
+		return value, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return value, nil
+}
+
+// rowScanner scans the columns of a row into a T.
+type rowScanner[T any] struct {
+	columns []string
+	// fields holds the index of each column's field, it is nil when T is scanned from a single column
+	fields [][]int
+}
+
+// newRowScanner matches the columns of the rows to the fields of T.
+func newRowScanner[T any](rows *sql.Rows) (*rowScanner[T], error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	typ := reflect.TypeFor[T]()
+	s := &rowScanner[T]{columns: columns}
+
+	if !scannedAsStruct(typ) {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("cannot scan %d columns into %s, a struct is required for more than one column", len(columns), typ)
+		}
+		return s, nil
+	}
+
+	fields, err := structFields(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := map[string]bool{}
+	for _, column := range columns {
+		field, ok := fields.byTag[column]
+		if !ok {
+			field, ok = fields.byName[strings.ToLower(column)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("column %q has no matching field in %s", column, typ)
+		}
+		if matched[field.key()] {
+			return nil, fmt.Errorf("column %q matches field %s.%s of another column", column, typ, field.name)
+		}
+		matched[field.key()] = true
+		s.fields = append(s.fields, field.index)
+	}
+
+	for _, field := range fields.ordered {
+		if field.tag != "" && !matched[field.key()] {
+			return nil, fmt.Errorf("field %s.%s has no matching column %q", typ, field.name, field.tag)
+		}
+	}
+
+	return s, nil
+}
+
+func (s *rowScanner[T]) scan(rows *sql.Rows, dest *T) error {
+	values := make([]any, len(s.columns))
+	pointers := make([]any, len(s.columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := rows.Scan(pointers...); err != nil {
+		return fmt.Errorf("failed to scan row: %w", err)
+	}
+
+	target := reflect.ValueOf(dest).Elem()
+	if s.fields == nil {
+		if err := assign(target, values[0]); err != nil {
+			return fmt.Errorf("failed to scan column %q into %s: %w", s.columns[0], target.Type(), err)
+		}
+		return nil
+	}
+
+	for i, index := range s.fields {
+		field := target.FieldByIndex(index)
+		if err := assign(field, values[i]); err != nil {
+			return fmt.Errorf("failed to scan column %q into field %s.%s: %w", s.columns[i], target.Type(), target.Type().FieldByIndex(index).Name, err)
+		}
+	}
+	return nil
+}
+
+// scannedAsStruct reports whether the columns of a row are scanned into the fields of typ.
+func scannedAsStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeType && !reflect.PointerTo(typ).Implements(scannerType)
+}
+
+type structField struct {
+	name  string
+	tag   string
+	index []int
+}
+
+// key identifies the field, names are not unique when structs are embedded.
+func (f structField) key() string {
+	return fmt.Sprint(f.index)
+}
+
+type fieldSet struct {
+	byTag  map[string]structField
+	byName map[string]structField
+	// ordered are the fields in declaration order, used to scan rows
+	ordered []structField
+}
+
+// structFields returns the fields of typ that columns can be scanned into.
+func structFields(typ reflect.Type) (*fieldSet, error) {
+	fields := &fieldSet{byTag: map[string]structField{}, byName: map[string]structField{}}
+	if err := fields.add(typ, nil); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (f *fieldSet) add(typ reflect.Type, parent []int) error {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		tag, tagged := field.Tag.Lookup("trino")
+		if tag == "-" {
+			continue
+		}
+
+		index := append(append([]int{}, parent...), i)
+		if field.Anonymous && !tagged && scannedAsStruct(field.Type) {
+			if err := f.add(field.Type, index); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		sf := structField{name: field.Name, tag: tag, index: index}
+		f.ordered = append(f.ordered, sf)
+		switch {
+		case tagged:
+			if _, ok := f.byTag[tag]; ok {
+				return fmt.Errorf("more than one field of %s is tagged %q", typ, tag)
+			}
+			f.byTag[tag] = sf
+		case f.byName[strings.ToLower(field.Name)].index == nil:
+			// the first field declared with a name wins, as outer fields are declared before embedded ones
+			f.byName[strings.ToLower(field.Name)] = sf
+		}
+	}
+	return nil
+}
+
+// assign converts a value returned by the Trino driver, or nested in an array, map or row, and stores it in dst.
+func assign(dst reflect.Value, src any) error {
+	if dst.CanAddr() && dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+
+	if src == nil {
+		switch dst.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+			dst.SetZero()
+			return nil
+		}
+		return fmt.Errorf("cannot scan NULL into %s, use a pointer or a sql.Null type", dst.Type())
+	}
+
+	if dst.Type() == timeType {
+		t, err := toTime(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		value := reflect.New(dst.Type().Elem())
+		if err := assign(value.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(value)
+		return nil
+
+	case reflect.Interface:
+		if reflect.TypeOf(src).AssignableTo(dst.Type()) {
+			dst.Set(reflect.ValueOf(src))
+			return nil
+		}
+
+	case reflect.String:
+		switch v := src.(type) {
+		case string:
+			dst.SetString(v)
+			return nil
+		case []byte:
+			dst.SetString(string(v))
+			return nil
+		case json.Number:
+			dst.SetString(v.String())
+			return nil
+		}
+
+	case reflect.Bool:
+		if v, ok := src.(bool); ok {
+			dst.SetBool(v)
+			return nil
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toInt64(src)
+		if err != nil {
+			return err
+		}
+		if n < 0 || dst.OverflowUint(uint64(n)) {
+			return fmt.Errorf("value %d overflows %s", n, dst.Type())
+		}
+		dst.SetUint(uint64(n))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, err := toFloat64(src)
+		if err != nil {
+			return err
+		}
+		if dst.OverflowFloat(n) {
+			return fmt.Errorf("value %v overflows %s", n, dst.Type())
+		}
+		dst.SetFloat(n)
+		return nil
+
+	case reflect.Slice:
+		if dst.Type().Elem().Kind() == reflect.Uint8 {
+			switch v := src.(type) {
+			case []byte:
+				dst.SetBytes(append([]byte{}, v...))
+				return nil
+			case string:
+				dst.SetBytes([]byte(v))
+				return nil
+			}
+		}
+		items, ok := src.([]any)
+		if !ok {
+			break
+		}
+		slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assign(slice.Index(i), item); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+
+	case reflect.Map:
+		entries, ok := src.(map[string]any)
+		if !ok {
+			break
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(entries))
+		for key, entry := range entries {
+			k := reflect.New(dst.Type().Key()).Elem()
+			if err := assign(k, key); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(v, entry); err != nil {
+				return fmt.Errorf("key %q: %w", key, err)
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+		return nil
+
+	case reflect.Struct:
+		items, ok := src.([]any)
+		if !ok {
+			break
+		}
+		fields, err := structFields(dst.Type())
+		if err != nil {
+			return err
+		}
+		if len(fields.ordered) != len(items) {
+			return fmt.Errorf("cannot scan row with %d fields into %s with %d fields", len(items), dst.Type(), len(fields.ordered))
+		}
+		for i, field := range fields.ordered {
+			if err := assign(dst.FieldByIndex(field.index), items[i]); err != nil {
+				return fmt.Errorf("field %s: %w", field.name, err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T into %s", src, dst.Type())
+}
+
+func toInt64(src any) (int64, error) {
+	switch v := src.(type) {
+	case int64:
+		return v, nil
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+	case json.Number:
+		return v.Int64()
+	case string:
+		// decimals with a scale of 0 and map keys
+		return strconv.ParseInt(v, 10, 64)
+	}
+	return 0, fmt.Errorf("cannot scan %T %v into an integer", src, src)
+}
+
+func toFloat64(src any) (float64, error) {
+	switch v := src.(type) {
+	case float64:
+		return v, nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		// decimals, and NaN and Infinity which Trino returns as strings
+		return strconv.ParseFloat(v, 64)
+	}
+	return 0, fmt.Errorf("cannot scan %T %v into a float", src, src)
+}
+
+// toTime converts a time returned by the driver or a time string nested in an array, map or row.
+func toTime(src any) (time.Time, error) {
+	switch v := src.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		// the driver only parses nested times inside arrays so reuse its parsing of the formats Trino returns
+		var times trino.NullSliceTime
+		if err := times.Scan([]any{v}); err != nil {
+			return time.Time{}, err
+		}
+		return times.SliceTime[0].Time, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot scan %T %v into time.Time", src, src)
+}
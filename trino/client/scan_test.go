@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/trinodb/trino-go-client/trino"
+
+	mocktrino "github.com/kanopy-platform/go-library/trino/testing"
+)
+
+// newRowsClient returns a client whose queries return the columns and rows.
+func newRowsClient(t *testing.T, columns []string, rows ...[]driver.Value) *Client {
+	return newMockClient(t, testRetryPolicy(0), &mocktrino.MockStmt{
+		Rows: []*mocktrino.StubRows{{Cols: columns, Values: rows}},
+		Err:  []error{nil},
+	})
+}
+
+func reflectValue[T any](v *T) reflect.Value {
+	return reflect.ValueOf(v).Elem()
+}
+
+type Base struct {
+	ID int64 `trino:"id"`
+}
+
+type Location struct {
+	Lat  float64
+	Long float64
+	Seen *time.Time
+}
+
+type Order struct {
+	Base
+	Total     string           `trino:"total"`
+	Amount    float64          `trino:"amount"`
+	Tags      []string         `trino:"tags"`
+	Discount  *float64         `trino:"discount"`
+	Attrs     map[string]int64 `trino:"attrs"`
+	CreatedAt time.Time        `trino:"created_at"`
+	Location  Location         `trino:"location"`
+	Note      sql.NullString   `trino:"note"`
+	Region    string
+	Ignored   string `trino:"-"`
+}
+
+func TestQueryInto(t *testing.T) {
+	t.Parallel()
+
+	utc := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	client := newRowsClient(t,
+		[]string{"id", "total", "amount", "tags", "discount", "attrs", "created_at", "location", "note", "REGION"},
+		[]driver.Value{int64(1), "12.50", "12.50", []any{"a", "b"}, nil, map[string]any{"x": json.Number("1")}, utc, []any{json.Number("1.5"), json.Number("-2"), "2024-01-02 03:04:05.000 UTC"}, nil, "us"},
+		[]driver.Value{int64(2), "3.00", "3", nil, 0.25, nil, utc, []any{json.Number("0"), json.Number("0"), nil}, "fragile", "eu"},
+	)
+
+	orders, err := QueryInto[Order](context.Background(), client, "SELECT * FROM orders")
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+
+	discount := 0.25
+	assert.Equal(t, Order{
+		Base:      Base{ID: 1},
+		Total:     "12.50",
+		Amount:    12.5,
+		Tags:      []string{"a", "b"},
+		Attrs:     map[string]int64{"x": 1},
+		CreatedAt: utc,
+		Location:  Location{Lat: 1.5, Long: -2, Seen: &utc},
+		Region:    "us",
+	}, orders[0])
+	assert.Equal(t, Order{
+		Base:      Base{ID: 2},
+		Total:     "3.00",
+		Amount:    3,
+		Discount:  &discount,
+		CreatedAt: utc,
+		Note:      sql.NullString{String: "fragile", Valid: true},
+		Region:    "eu",
+	}, orders[1])
+}
+
+func TestQueryIntoErrors(t *testing.T) {
+	t.Parallel()
+
+	type Named struct {
+		Name string `trino:"name"`
+		Size int64  `trino:"size"`
+	}
+
+	testcases := map[string]struct {
+		columns []string
+		row     []driver.Value
+		err     string
+	}{
+		"unknown column": {
+			columns: []string{"name", "size", "color"},
+			row:     []driver.Value{"a", int64(1), "red"},
+			err:     `column "color" has no matching field in client.Named`,
+		},
+		"missing column": {
+			columns: []string{"name"},
+			row:     []driver.Value{"a"},
+			err:     `field client.Named.Size has no matching column "size"`,
+		},
+		"null into value": {
+			columns: []string{"name", "size"},
+			row:     []driver.Value{"a", nil},
+			err:     `failed to scan column "size" into field client.Named.Size: cannot scan NULL into int64, use a pointer or a sql.Null type`,
+		},
+		"mismatched type": {
+			columns: []string{"name", "size"},
+			row:     []driver.Value{true, int64(1)},
+			err:     `failed to scan column "name" into field client.Named.Name: cannot scan bool into string`,
+		},
+	}
+
+	for name, tc := range testcases {
+		client := newRowsClient(t, tc.columns, tc.row)
+		_, err := QueryInto[Named](context.Background(), client, "SELECT 1")
+		assert.EqualError(t, err, tc.err, name)
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	count, err := QueryOne[int64](ctx, newRowsClient(t, []string{"_col0"}, []driver.Value{int64(42)}), "SELECT count(*) FROM orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+
+	seen, err := QueryOne[trino.NullTime](ctx, newRowsClient(t, []string{"seen"}, []driver.Value{nil}), "SELECT max(seen) FROM orders")
+	require.NoError(t, err)
+	assert.False(t, seen.Valid)
+
+	_, err = QueryOne[int64](ctx, newRowsClient(t, []string{"_col0"}), "SELECT 1 WHERE false")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = QueryOne[int64](ctx, newRowsClient(t, []string{"_col0"}, []driver.Value{int64(1)}, []driver.Value{int64(2)}), "SELECT 1 UNION ALL SELECT 2")
+	assert.ErrorIs(t, err, ErrMultipleRows)
+
+	_, err = QueryOne[int64](ctx, newRowsClient(t, []string{"a", "b"}, []driver.Value{int64(1), int64(2)}), "SELECT 1, 2")
+	assert.EqualError(t, err, "cannot scan 2 columns into int64, a struct is required for more than one column")
+}
+
+func TestAssign(t *testing.T) {
+	t.Parallel()
+
+	var small int8
+	assert.ErrorContains(t, assign(reflectValue(&small), int64(300)), "overflows int8")
+
+	var unsigned uint
+	assert.ErrorContains(t, assign(reflectValue(&unsigned), int64(-1)), "overflows uint")
+
+	var keyed map[int]string
+	require.NoError(t, assign(reflectValue(&keyed), map[string]any{"1": "one"}))
+	assert.Equal(t, map[int]string{1: "one"}, keyed)
+
+	var nested [][]int64
+	require.NoError(t, assign(reflectValue(&nested), []any{[]any{json.Number("1")}, nil}))
+	assert.Equal(t, [][]int64{{1}, nil}, nested)
+
+	var zoned time.Time
+	require.NoError(t, assign(reflectValue(&zoned), "2024-01-02 03:04:05.123 America/New_York"))
+	assert.Equal(t, "America/New_York", zoned.Location().String())
+	assert.Equal(t, 123*time.Millisecond, time.Duration(zoned.Nanosecond()))
+
+	var row struct{ A, B int64 }
+	assert.EqualError(t, assign(reflectValue(&row), []any{json.Number("1")}), "cannot scan row with 1 fields into struct { A int64; B int64 } with 2 fields")
+
+	var raw any
+	require.NoError(t, assign(reflectValue(&raw), []any{"a"}))
+	assert.Equal(t, []any{"a"}, raw)
+}
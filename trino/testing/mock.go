@@ -3,6 +3,7 @@ package testing
 import (
 	context "context"
 	driver "database/sql/driver"
+	"io"
 )
 
 type MockDriver struct {
@@ -41,6 +42,9 @@ type MockStmt struct {
 	count int
 	Err   []error
 	Rows  []*StubRows
+	// Results are returned by ExecContext along with Err, either may be shorter than the other.
+	// ExecContext returns driver.RowsAffected(0) when there is no result and a nil error when there is no error.
+	Results []driver.Result
 }
 
 func (m *MockStmt) Close() error {
@@ -51,6 +55,23 @@ func (m *MockStmt) Exec(args []driver.Value) (driver.Result, error) {
 	return nil, driver.ErrBadConn
 }
 
+func (m *MockStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if m.count >= max(len(m.Err), len(m.Results)) {
+		m.count = 0
+	}
+	var result driver.Result = driver.RowsAffected(0)
+	if m.count < len(m.Results) {
+		result = m.Results[m.count]
+	}
+	var err error
+	if m.count < len(m.Err) {
+		err = m.Err[m.count]
+	}
+	m.count++
+
+	return result, err
+}
+
 func (m *MockStmt) NumInput() int {
 	return 0
 }
@@ -70,14 +91,30 @@ func (m *MockStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (
 	return rows, err
 }
 
-type StubRows struct{}
+// StubRows returns Values, one driver.Value per column in Cols for each row.
+type StubRows struct {
+	Cols   []string
+	Values [][]driver.Value
+	// Closed is set when the rows are closed
+	Closed bool
+	next   int
+}
 
 func (m *StubRows) Columns() []string {
-	return []string{}
+	if m.Cols == nil {
+		return []string{}
+	}
+	return m.Cols
 }
 func (m *StubRows) Close() error {
+	m.Closed = true
 	return nil
 }
 func (m *StubRows) Next(dest []driver.Value) error {
+	if m.next >= len(m.Values) {
+		return io.EOF
+	}
+	copy(dest, m.Values[m.next])
+	m.next++
 	return nil
 }